@@ -0,0 +1,98 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// AllowedVars checks that 'expr' only refers to the variables listed in
+// 'allowed', or to functions (builtin or user-defined), Go's predeclared
+// identifiers (true, len, ...), and imports of s.
+//
+// The variables of s are not allowed, unless listed. An error is returned
+// for each identifier that is not allowed, with its position, joined with
+// [errors.Join]. The expression is not evaluated.
+func (s Scope) AllowedVars(expr string, allowed []string) error {
+	x, fset, err := s.Parse(expr)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	check := func(id *ast.Ident) {
+		if !slices.Contains(allowed, id.Name) && !s.known(id.Name) {
+			errs = append(errs, fmt.Errorf("%v: %s is not an allowed variable", fset.Position(id.Pos()), id.Name))
+		}
+	}
+	ast.Inspect(x, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// Only the package name, not the selected variable, nor
+			// the name of an import under a root namespace.
+			x := n.X
+			if inner, ok := x.(*ast.SelectorExpr); ok {
+				x = inner.X
+			}
+			if id, ok := x.(*ast.Ident); ok {
+				check(id)
+				return false
+			}
+		case *ast.Ident:
+			check(n)
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// IsConstant evaluates 'expr', and reports whether its value only depends
+// on literals and functions, not on the variables or imports of s: "2 *
+// sqrt(2)" is constant, "2 * x" is not.
+//
+// An error is returned if 'expr' cannot be evaluated in s at all.
+func (s Scope) IsConstant(expr string) (bool, error) {
+	if _, err := s.eval(expr); err != nil {
+		return false, err
+	}
+	c := s
+	c.p, c.resolver, c.arrays, c.maps = nil, nil, nil, nil
+	_, err := c.eval(expr)
+	return err == nil, nil
+}
+
+// known reports whether 'name' is a function, a custom operator, a
+// predeclared identifier, an import, or a root namespace of imports in s.
+func (s Scope) known(name string) bool {
+	if _, ok := builtins[name]; ok {
+		return true
+	}
+	if slices.ContainsFunc(s.operators, func(op operator) bool { return op.symbol == name }) {
+		return true
+	}
+	if _, ok := s.funcs[name]; ok {
+		return true
+	}
+	if _, ok := stdlib[name]; ok && s.std {
+		return true
+	}
+	if types.Universe.Lookup(name) != nil {
+		return true
+	}
+	p := s.env()
+	if p == nil {
+		return false
+	}
+	if _, ok := p.Scope().Lookup(name).(*types.PkgName); ok {
+		return true
+	}
+	// A root namespace, see ImportUnder.
+	for _, n := range p.Scope().Names() {
+		if _, ok := p.Scope().Lookup(n).(*types.PkgName); ok && strings.HasPrefix(n, name+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package calc
+
+import "testing"
+
+func TestAllowedVars(t *testing.T) {
+	var lib Scope
+	lib.Assign("K", "1000")
+	var c Scope
+	c.Assign("secret", "42")
+	c.Import("units", &lib)
+	c.DefineFunc("sq", []string{"x"}, "x*x")
+	c = c.WithMathNamespace()
+	allowed := []string{"price", "qty"}
+
+	for _, expr := range []string{
+		"price * qty",
+		"sqrt(price) + sq(qty) + len(\"a\")",
+		"price * units.K * math.Pi",
+		"price > 0 && true",
+	} {
+		if err := c.AllowedVars(expr, allowed); err != nil {
+			t.Errorf("AllowedVars(%q) unexpected error: %v", expr, err)
+		}
+	}
+
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"price * tax", "eval:1:9: tax is not an allowed variable"},
+		{"secret + 1", "eval:1:1: secret is not an allowed variable"},
+		{"a + b", "eval:1:1: a is not an allowed variable\neval:1:5: b is not an allowed variable"},
+		{"other.K", "eval:1:1: other is not an allowed variable"},
+		{"price +", "eval:1:8: expected operand, found 'EOF'"},
+	}
+	for _, test := range tests {
+		err := c.AllowedVars(test.expr, allowed)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("AllowedVars(%q) error = %v, want %v", test.expr, err, test.err)
+		}
+	}
+}
+
+func TestIsConstant(t *testing.T) {
+	var lib Scope
+	lib.Assign("K", "1000")
+	var c Scope
+	c.Assign("x", "42")
+	c.Import("units", &lib)
+	c.AssignSlice("data", []float64{1, 2})
+	c.AssignMap("m", map[string]float64{"a": 1})
+	c.DefineFunc("sq", []string{"x"}, "x*x")
+	c.DefineFunc("addx", []string{"y"}, "x + y")
+	c = c.WithMathNamespace()
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"1 + 2", true},
+		{"2*sqrt(2) + float64(len(\"ab\"))", true},
+		{"sq(3) + math.Pi", true},
+		{"2 * x", false},
+		{"units.K", false},
+		{"addx(1)", false},
+		{"data[0]", false},
+		{`m["a"]`, false},
+	}
+	for _, test := range tests {
+		got, err := c.IsConstant(test.expr)
+		if err != nil {
+			t.Errorf("IsConstant(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("IsConstant(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"y + 1", "1 +"} {
+		if _, err := c.IsConstant(expr); err == nil {
+			t.Errorf("IsConstant(%q) expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package calc
+
+import (
+	"go/ast"
+)
+
+// apply rewrites the expression 'x' recursively.
+//
+// 'pre' is called on each node before its children are rewritten, and 'post'
+// after. Both return the node to be used in place of the given one, and
+// either can be nil.
+func apply(x ast.Expr, pre, post func(ast.Expr) (ast.Expr, error)) (ast.Expr, error) {
+	var err error
+	if pre != nil {
+		if x, err = pre(x); err != nil {
+			return nil, err
+		}
+	}
+	rec := func(x *ast.Expr) {
+		if err == nil && *x != nil {
+			*x, err = apply(*x, pre, post)
+		}
+	}
+	switch n := x.(type) {
+	case *ast.BinaryExpr:
+		rec(&n.X)
+		rec(&n.Y)
+	case *ast.UnaryExpr:
+		rec(&n.X)
+	case *ast.ParenExpr:
+		rec(&n.X)
+	case *ast.StarExpr:
+		rec(&n.X)
+	case *ast.SelectorExpr:
+		rec(&n.X)
+	case *ast.IndexExpr:
+		rec(&n.X)
+		rec(&n.Index)
+	case *ast.SliceExpr:
+		rec(&n.X)
+		rec(&n.Low)
+		rec(&n.High)
+		rec(&n.Max)
+	case *ast.CallExpr:
+		rec(&n.Fun)
+		for i := range n.Args {
+			rec(&n.Args[i])
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if post != nil {
+		return post(x)
+	}
+	return x, nil
+}
+
+// copyExpr returns a copy of 'x' that can be rewritten by apply without
+// changing 'x'.
+//
+// Only the nodes apply walks through are copied, others are shared.
+func copyExpr(x ast.Expr) ast.Expr {
+	switch n := x.(type) {
+	case *ast.BinaryExpr:
+		c := *n
+		c.X, c.Y = copyExpr(n.X), copyExpr(n.Y)
+		return &c
+	case *ast.UnaryExpr:
+		c := *n
+		c.X = copyExpr(n.X)
+		return &c
+	case *ast.ParenExpr:
+		c := *n
+		c.X = copyExpr(n.X)
+		return &c
+	case *ast.StarExpr:
+		c := *n
+		c.X = copyExpr(n.X)
+		return &c
+	case *ast.SelectorExpr:
+		c := *n
+		c.X = copyExpr(n.X)
+		return &c
+	case *ast.IndexExpr:
+		c := *n
+		c.X, c.Index = copyExpr(n.X), copyExpr(n.Index)
+		return &c
+	case *ast.SliceExpr:
+		c := *n
+		c.X, c.Low, c.High, c.Max = copyExpr(n.X), copyExpr(n.Low), copyExpr(n.High), copyExpr(n.Max)
+		return &c
+	case *ast.CallExpr:
+		c := *n
+		c.Fun = copyExpr(n.Fun)
+		c.Args = make([]ast.Expr, len(n.Args))
+		for i, arg := range n.Args {
+			c.Args[i] = copyExpr(arg)
+		}
+		return &c
+	}
+	return x
+}
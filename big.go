@@ -0,0 +1,257 @@
+package calc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go/constant"
+	"math"
+	"math/big"
+	"math/bits"
+	"slices"
+)
+
+// BigFloat evaluates 'expr' as a *big.Float with 'prec' bits of mantissa.
+//
+// Builtin functions supporting arbitrary precision (sqrt, hypot and dist)
+// are computed at precision 'prec' too, instead of float64. Other builtins
+// are float64-only.
+//
+// Note that Go constant arithmetic is exact on rationals, but rounds
+// irrational values (like the result of sqrt) to 512 bits: higher
+// precisions are only honored by the builtin functions themselves.
+func (s Scope) BigFloat(expr string, prec uint) (*big.Float, error) {
+	if prec == 0 {
+		return nil, errors.New("precision must be positive")
+	}
+	e := s.newEvaluation()
+	e.prec = prec
+	tv, err := s.checkIn(e, expr)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := toRat(tv.Value)
+	if !ok {
+		return nil, fmt.Errorf("not representable as a float (%v): %q", tv.Value.Kind(), expr)
+	}
+	return new(big.Float).SetPrec(prec).SetRat(r), nil
+}
+
+// BigInt evaluates 'expr' as an exact *big.Int, without the limits of
+// int64.
+func (s Scope) BigInt(expr string) (*big.Int, error) {
+	val, err := s.eval(expr)
+	if err != nil {
+		return nil, err
+	}
+	ival := constant.ToInt(val)
+	if ival.Kind() == constant.Unknown {
+		return nil, fmt.Errorf("not representable as an int (%v): %q", val.Kind(), expr)
+	}
+	switch v := constant.Val(ival).(type) {
+	case int64:
+		return big.NewInt(v), nil
+	case *big.Int:
+		return new(big.Int).Set(v), nil
+	}
+	return nil, fmt.Errorf("not representable as an int (%v): %q", val.Kind(), expr)
+}
+
+// BigUint is like [Scope.BigInt], but 'expr' must not be negative.
+func (s Scope) BigUint(expr string) (*big.Int, error) {
+	i, err := s.BigInt(expr)
+	if err != nil {
+		return nil, err
+	}
+	if i.Sign() < 0 {
+		return nil, fmt.Errorf("not representable as an unsigned int (negative): %q", expr)
+	}
+	return i, nil
+}
+
+// IsPrime evaluates the integer expression 'expr', and reports whether it
+// is a prime number: IsPrime("2**31 - 1") is true, with the power operator
+// (see [Scope.WithPowerOperator]).
+//
+// The test is [big.Int.ProbablyPrime], that is 100% accurate below 2**64,
+// and practically accurate above. Negative values are an error.
+func (s Scope) IsPrime(expr string) (bool, error) {
+	i, err := s.BigUint(expr)
+	if err != nil {
+		return false, err
+	}
+	return i.ProbablyPrime(20), nil
+}
+
+// Factorize evaluates the integer expression 'expr', and returns its prime
+// factorization, as a map of primes to their exponents: Factorize("360")
+// is {2: 3, 3: 2, 5: 1}, and Factorize("1") is empty.
+//
+// The value must be positive, and fit in an int64: this bounds the time of
+// factoring, that uses trial division for small factors, and Pollard's rho
+// algorithm for the others.
+func (s Scope) Factorize(expr string) (map[int64]int, error) {
+	n, err := s.Int(expr)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("cannot factorize a non-positive integer (%d): %q", n, expr)
+	}
+	factors := make(map[int64]int)
+	m := uint64(n)
+	for p := uint64(2); p < 1000 && p*p <= m; p++ {
+		for m%p == 0 {
+			factors[int64(p)]++
+			m /= p
+		}
+	}
+	factorize(m, factors)
+	return factors, nil
+}
+
+// factorize adds the prime factors of 'n' to 'factors'.
+func factorize(n uint64, factors map[int64]int) {
+	switch {
+	case n == 1:
+	case new(big.Int).SetUint64(n).ProbablyPrime(20): // exact for uint64.
+		factors[int64(n)]++
+	default:
+		d := rho(n)
+		factorize(d, factors)
+		factorize(n/d, factors)
+	}
+}
+
+// rho returns a non trivial divisor of the odd composite 'n', using
+// Pollard's rho algorithm.
+func rho(n uint64) uint64 {
+	mulMod := func(a, b uint64) uint64 {
+		hi, lo := bits.Mul64(a, b)
+		_, r := bits.Div64(hi, lo, n)
+		return r
+	}
+	for c := uint64(1); ; c++ {
+		f := func(x uint64) uint64 { return (mulMod(x, x) + c) % n }
+		x, y, d := uint64(2), uint64(2), uint64(1)
+		for d == 1 {
+			x, y = f(x), f(f(y))
+			diff := x - y
+			if x < y {
+				diff = y - x
+			}
+			d = gcd(diff, n)
+		}
+		if d != n {
+			return d
+		}
+	}
+}
+
+// gcd returns the greatest common divisor of 'a' and 'b'.
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// ByteLen evaluates the integer expression 'expr', and returns the minimal
+// number of bytes needed to represent it.
+//
+// Non-negative values are measured as unsigned integers: 255 needs 1 byte,
+// 256 needs 2, and 0 needs none, like in [big.Int.Bytes]. Negative values
+// are measured in two's complement, sign bit included: -128 needs 1 byte,
+// and -129 needs 2.
+func (s Scope) ByteLen(expr string) (int, error) {
+	i, err := s.BigInt(expr)
+	if err != nil {
+		return 0, err
+	}
+	if i.Sign() >= 0 {
+		return (i.BitLen() + 7) / 8, nil
+	}
+	// -v-1 is non-negative, and has the same bits as v, but the sign.
+	m := new(big.Int).Neg(i)
+	bits := m.Sub(m, big.NewInt(1)).BitLen() + 1
+	return (bits + 7) / 8, nil
+}
+
+// Bytes evaluates the non-negative integer expression 'expr', and encodes
+// it as an unsigned integer of 'size' bytes, in the byte order 'order': for
+// instance, "1 << 255" in 32 bytes, big-endian.
+//
+// An error is returned if the value is negative, or does not fit in 'size'
+// bytes. See [Scope.SignedBytes] for negative values.
+func (s Scope) Bytes(expr string, order binary.ByteOrder, size int) ([]byte, error) {
+	i, err := s.BigUint(expr)
+	if err != nil {
+		return nil, err
+	}
+	if i.BitLen() > 8*size {
+		return nil, fmt.Errorf("not representable in %d bytes: %q", size, expr)
+	}
+	return encode(i, order, size), nil
+}
+
+// SignedBytes is like [Scope.Bytes], but encodes the integer in two's
+// complement: values must be in the range [-2**(8*size-1), 2**(8*size-1)).
+func (s Scope) SignedBytes(expr string, order binary.ByteOrder, size int) ([]byte, error) {
+	i, err := s.BigInt(expr)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("not representable in %d bytes: %q", size, expr)
+	}
+	lim := new(big.Int).Lsh(big.NewInt(1), uint(8*size-1))
+	if i.Cmp(lim) >= 0 || i.Cmp(new(big.Int).Neg(lim)) < 0 {
+		return nil, fmt.Errorf("not representable in %d bytes: %q", size, expr)
+	}
+	if i.Sign() < 0 {
+		i.Add(i, lim.Lsh(lim, 1))
+	}
+	return encode(i, order, size), nil
+}
+
+// encode writes the non-negative 'i' into 'size' bytes, in 'order'.
+func encode(i *big.Int, order binary.ByteOrder, size int) []byte {
+	b := i.FillBytes(make([]byte, size))
+	// Find out the order of 'order', whatever its implementation.
+	probe := make([]byte, 2)
+	order.PutUint16(probe, 1)
+	if probe[0] == 1 {
+		slices.Reverse(b)
+	}
+	return b
+}
+
+// Float64Bits evaluates 'expr' as a float64, and returns its IEEE 754
+// binary representation, like [math.Float64bits]: "1" is
+// 0x3ff0000000000000.
+//
+// The value is rounded to the nearest float64, and an error is returned if
+// it overflows to an infinity. Constants are never NaN, nor -0.
+func (s Scope) Float64Bits(expr string) (uint64, error) {
+	f, err := s.Float64(expr)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsInf(f, 0) {
+		return 0, fmt.Errorf("overflows float64: %q", expr)
+	}
+	return math.Float64bits(f), nil
+}
+
+// Float32Bits is like [Scope.Float64Bits], but for a float32: "1" is
+// 0x3f800000.
+func (s Scope) Float32Bits(expr string) (uint32, error) {
+	f, err := s.Float32(expr)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsInf(float64(f), 0) {
+		return 0, fmt.Errorf("overflows float32: %q", expr)
+	}
+	return math.Float32bits(f), nil
+}
@@ -0,0 +1,230 @@
+package calc
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"maps"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestBigUint(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"0", "0"},
+		{"1<<64", "18446744073709551616"},
+		{"1<<256 - 1", "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{"(1<<256) / (1<<200)", "72057594037927936"},
+		{"1e30", "1000000000000000000000000000000"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.BigUint(test.expr)
+		if err != nil {
+			t.Errorf("BigUint(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		want, _ := new(big.Int).SetString(test.want, 10)
+		if got.Cmp(want) != 0 {
+			t.Errorf("BigUint(%q) = %v, want %v", test.expr, got, want)
+		}
+	}
+
+	for _, expr := range []string{"-1", "-(1<<256)", "1.5", `"1"`} {
+		if _, err := (Scope{}).BigUint(expr); err == nil {
+			t.Errorf("BigUint(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestBigInt(t *testing.T) {
+	got, err := Scope{}.BigInt("-(1<<100)")
+	if err != nil {
+		t.Fatalf("BigInt() unexpected error: %v", err)
+	}
+	want := new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 100))
+	if got.Cmp(want) != 0 {
+		t.Errorf("BigInt() = %v, want %v", got, want)
+	}
+}
+
+func TestIsPrime(t *testing.T) {
+	s := Scope{}.WithPowerOperator()
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"0", false},
+		{"1", false},
+		{"2", true},
+		{"91", false},
+		{"97", true},
+		{"2**31 - 1", true},
+		{"2**32 + 1", false},
+		{"2**127 - 1", true},
+		{"10.0", false},
+	}
+	for _, test := range tests {
+		got, err := s.IsPrime(test.expr)
+		if err != nil {
+			t.Errorf("IsPrime(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("IsPrime(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"-7", "7.5", `"7"`} {
+		if _, err := s.IsPrime(expr); err == nil {
+			t.Errorf("IsPrime(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestFactorize(t *testing.T) {
+	s := Scope{}.WithPowerOperator()
+	tests := []struct {
+		expr string
+		want map[int64]int
+	}{
+		{"1", map[int64]int{}},
+		{"360", map[int64]int{2: 3, 3: 2, 5: 1}},
+		{"97", map[int64]int{97: 1}},
+		{"2**32 + 1", map[int64]int{641: 1, 6700417: 1}},
+		{"1000003 * 1000033", map[int64]int{1000003: 1, 1000033: 1}},
+		{"4294967291 * 2147483647", map[int64]int{4294967291: 1, 2147483647: 1}},
+		{"1000003**3", map[int64]int{1000003: 3}},
+		{"2**62", map[int64]int{2: 62}},
+		{"2**63 - 1", map[int64]int{7: 2, 73: 1, 127: 1, 337: 1, 92737: 1, 649657: 1}},
+	}
+	for _, test := range tests {
+		got, err := s.Factorize(test.expr)
+		if err != nil {
+			t.Errorf("Factorize(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if !maps.Equal(got, test.want) {
+			t.Errorf("Factorize(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"0", "-12", "1.5", "2**63", `"12"`} {
+		if _, err := s.Factorize(expr); err == nil {
+			t.Errorf("Factorize(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestByteLen(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{"0", 0},
+		{"1", 1},
+		{"255", 1},
+		{"256", 2},
+		{"1 << 255", 32},
+		{"1<<256 - 1", 32},
+		{"-1", 1},
+		{"-128", 1},
+		{"-129", 2},
+		{"-32768", 2},
+		{"-32769", 3},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.ByteLen(test.expr)
+		if err != nil {
+			t.Errorf("ByteLen(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ByteLen(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"1.5", `"a"`, "1i"} {
+		if _, err := (Scope{}).ByteLen(expr); err == nil {
+			t.Errorf("ByteLen(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		expr   string
+		order  binary.ByteOrder
+		size   int
+		signed bool
+		want   string // hexadecimal
+	}{
+		{"0x0102", binary.BigEndian, 4, false, "00000102"},
+		{"0x0102", binary.LittleEndian, 4, false, "02010000"},
+		{"1 << 255", binary.BigEndian, 32, false, "8000000000000000000000000000000000000000000000000000000000000000"},
+		{"255", binary.BigEndian, 1, false, "ff"},
+		{"-1", binary.BigEndian, 2, true, "ffff"},
+		{"-2", binary.LittleEndian, 2, true, "feff"},
+		{"-128", binary.BigEndian, 1, true, "80"},
+		{"127", binary.BigEndian, 1, true, "7f"},
+	}
+	for _, test := range tests {
+		f := Scope{}.Bytes
+		if test.signed {
+			f = Scope{}.SignedBytes
+		}
+		got, err := f(test.expr, test.order, test.size)
+		if err != nil {
+			t.Errorf("Bytes(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if hex.EncodeToString(got) != test.want {
+			t.Errorf("Bytes(%q) = %x, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{"-1", "256", "1.5"} {
+		if _, err := (Scope{}).Bytes(expr, binary.BigEndian, 1); err == nil {
+			t.Errorf("Bytes(%q) expected an error", expr)
+		}
+	}
+	for _, expr := range []string{"128", "-129"} {
+		if _, err := (Scope{}).SignedBytes(expr, binary.BigEndian, 1); err == nil {
+			t.Errorf("SignedBytes(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestFloatBits(t *testing.T) {
+	tests := []struct {
+		expr string
+		b64  uint64
+		b32  uint32
+	}{
+		{"1", 0x3ff0000000000000, 0x3f800000},
+		{"-2", 0xc000000000000000, 0xc0000000},
+		{"0", 0, 0},
+		{"0.1", 0x3fb999999999999a, 0x3dcccccd},
+		{"1 + 0i", 0x3ff0000000000000, 0x3f800000},
+	}
+	for _, test := range tests {
+		b64, err := Scope{}.Float64Bits(test.expr)
+		if err != nil || b64 != test.b64 {
+			t.Errorf("Float64Bits(%q) = %#x, %v, want %#x", test.expr, b64, err, test.b64)
+		}
+		if f, _ := (Scope{}).Float64(test.expr); math.Float64frombits(b64) != f {
+			t.Errorf("Float64Bits(%q) does not round trip to %v", test.expr, f)
+		}
+		b32, err := Scope{}.Float32Bits(test.expr)
+		if err != nil || b32 != test.b32 {
+			t.Errorf("Float32Bits(%q) = %#x, %v, want %#x", test.expr, b32, err, test.b32)
+		}
+	}
+	for _, expr := range []string{"1e400", "1i", `"1"`, "true"} {
+		if _, err := (Scope{}).Float64Bits(expr); err == nil {
+			t.Errorf("Float64Bits(%q) expected an error", expr)
+		}
+	}
+	if _, err := (Scope{}).Float32Bits("1e39"); err == nil {
+		t.Errorf("Float32Bits(%q) expected an error", "1e39")
+	}
+}
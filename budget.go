@@ -0,0 +1,54 @@
+package calc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// budgetPeriod is the period after which a budget is refilled.
+const budgetPeriod = time.Minute
+
+// budget is the evaluation time available to a Scope and its copies.
+type budget struct {
+	mu    sync.Mutex
+	limit time.Duration    // time available per period.
+	spent time.Duration    // time spent in the current period.
+	start time.Time        // of the current period.
+	now   func() time.Time // the clock, replaced in tests.
+}
+
+// WithBudget returns a copy of s whose evaluations can only take 'd' of
+// cumulative time per minute, to protect a shared scope from a noisy user.
+//
+// Once the time spent evaluating in the current minute reaches 'd',
+// evaluations fail with [ErrBudgetExceeded], until the budget is refilled.
+// The first minute starts with the first evaluation, and the next one
+// starts with the first evaluation after it. An evaluation in progress is
+// never interrupted, so the budget can be overspent by one evaluation.
+//
+// Copies of the returned Scope share the same budget, like they share the
+// same variables. Calling WithBudget again starts a new, independent one.
+func (s Scope) WithBudget(d time.Duration) Scope {
+	s.budget = &budget{limit: d, now: time.Now}
+	return s
+}
+
+// begin starts an evaluation of 'expr', and returns the function to call
+// at its end.
+func (b *budget) begin(expr string) (end func(), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.now()
+	if b.start.IsZero() || t.Sub(b.start) >= budgetPeriod {
+		b.start, b.spent = t, 0
+	}
+	if b.spent >= b.limit {
+		return nil, fmt.Errorf("%w: %v spent since %v: %q", ErrBudgetExceeded, b.spent, b.start.Format(time.TimeOnly), expr)
+	}
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.spent += b.now().Sub(t)
+	}, nil
+}
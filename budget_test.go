@@ -0,0 +1,43 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithBudget(t *testing.T) {
+	s := Scope{}.WithBudget(3 * time.Second)
+	// Each call to the clock takes one second, so each evaluation spends
+	// one second.
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.budget.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	c := s // copies share the budget.
+	for i, expr := range []string{"1", "1 +", "2"} {
+		c.Eval(expr)
+		if s.budget.spent != time.Duration(i+1)*time.Second {
+			t.Fatalf("spent = %v after %d evaluations, want %ds", s.budget.spent, i+1, i+1)
+		}
+	}
+	if _, err := s.Eval("3"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Eval() = %v, want ErrBudgetExceeded", err)
+	}
+	compiled, err := s.Compile("4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := compiled.Eval(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Compiled.Eval() = %v, want ErrBudgetExceeded", err)
+	}
+
+	clock = clock.Add(time.Minute)
+	if _, err := s.Eval("5"); err != nil {
+		t.Errorf("Eval() after a refill unexpected error: %v", err)
+	}
+	if _, err := (Scope{}).Eval("6"); err != nil {
+		t.Errorf("Eval() without a budget unexpected error: %v", err)
+	}
+}
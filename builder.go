@@ -0,0 +1,68 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ScopeBuilder builds a [Scope] fluently:
+//
+//	s, err := calc.NewBuilder().
+//		Assign("s", "1").
+//		Assign("m", "60*s").
+//		Import("time", &lib).
+//		Build()
+//
+// Errors do not stop the building: they are accumulated, and reported all
+// together by [ScopeBuilder.Build].
+type ScopeBuilder struct {
+	s    Scope
+	errs []error
+}
+
+// NewBuilder returns a builder of an empty Scope.
+func NewBuilder() *ScopeBuilder { return &ScopeBuilder{} }
+
+// Assign calls [Scope.Assign].
+func (b *ScopeBuilder) Assign(name, expr string) *ScopeBuilder {
+	if err := b.s.Assign(name, expr); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("%s: %w", name, err))
+	}
+	return b
+}
+
+// AssignValue calls [Scope.AssignValue], unsupported types are errors
+// instead of panics.
+func (b *ScopeBuilder) AssignValue(name string, v any) *ScopeBuilder {
+	if _, _, ok := constantOf(v); !ok {
+		b.errs = append(b.errs, fmt.Errorf("%s: unsupported type %T", name, v))
+		return b
+	}
+	b.s.AssignValue(name, v)
+	return b
+}
+
+// Import calls [Scope.Import].
+func (b *ScopeBuilder) Import(name string, lib *Scope) *ScopeBuilder {
+	if err := b.s.Import(name, lib); err != nil {
+		b.errs = append(b.errs, err)
+	}
+	return b
+}
+
+// DefineFunc calls [Scope.DefineFunc].
+func (b *ScopeBuilder) DefineFunc(name string, params []string, body string) *ScopeBuilder {
+	if err := b.s.DefineFunc(name, params, body); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("%s: %w", name, err))
+	}
+	return b
+}
+
+// Build returns the Scope built so far, and the errors met, joined with
+// [errors.Join].
+//
+// The Scope is returned even if there are errors: it contains all the
+// successful changes. The builder must not be used after Build.
+func (b *ScopeBuilder) Build() (Scope, error) {
+	return b.s, errors.Join(b.errs...)
+}
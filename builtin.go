@@ -0,0 +1,368 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// builtin is a function that can be called from expressions.
+type builtin struct {
+	nargs int // number of arguments, or -1 if variadic.
+	fn    func(args []constant.Value) (constant.Value, error)
+
+	// big is the arbitrary precision version of fn, if any.
+	big func(prec uint, args []*big.Float) (*big.Float, error)
+
+	// float reports whether fn computes in float64 with 'args', nil if it
+	// is always exact.
+	float func(args []constant.Value) bool
+}
+
+// builtins are the functions available in all expressions, on top of Go's
+// own builtins (real, imag, complex, min, max, ...).
+var builtins = map[string]builtin{
+	"sqrt":  {nargs: 1, fn: sqrt, big: bigSqrt, float: always},
+	"hypot": {nargs: 2, fn: hypot, big: bigHypot, float: always},
+	"dist":  {nargs: 4, fn: dist, big: bigDist, float: always},
+
+	"conj": {nargs: 1, fn: conj},
+	"re":   {nargs: 1, fn: re},
+	"im":   {nargs: 1, fn: im},
+	"abs":  {nargs: 1, fn: abs, float: absFloat},
+
+	"percentof": {nargs: 2, fn: percentof},
+	"pctchange": {nargs: 2, fn: pctchange},
+	"pow":       {nargs: 2, fn: pow, float: powFloat},
+
+	"lookup": {nargs: -1, fn: lookup},
+	"in":     {nargs: -1, fn: in},
+}
+
+// always is the float field of builtins always computing in float64.
+func always([]constant.Value) bool { return true }
+
+// call folds the call 'x' to a builtin into a temporary variable holding its
+// result.
+//
+// It must be called on the arguments first, so they are constant already.
+func (e *evaluation) call(x ast.Expr) (ast.Expr, error) {
+	call, ok := x.(*ast.CallExpr)
+	if !ok {
+		return x, nil
+	}
+	name, b, ok := e.builtin(call.Fun)
+	if !ok {
+		return x, nil
+	}
+	if b.nargs >= 0 && len(call.Args) != b.nargs {
+		return nil, e.errorf(call.Pos(), "%s expects %d arguments, got %d", name, b.nargs, len(call.Args))
+	}
+	if call.Ellipsis.IsValid() {
+		return nil, e.errorf(call.Ellipsis, "invalid use of ... in call to %s", name)
+	}
+	if id, ok := e.memo(call); ok {
+		return id, nil
+	}
+	args := make([]constant.Value, len(call.Args))
+	for i, arg := range call.Args {
+		v, err := e.value(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	fn := b.fn
+	if e.prec > 0 && b.big != nil {
+		fn = func(args []constant.Value) (constant.Value, error) { return callBig(b.big, e.prec, args) }
+	}
+	v, err := fn(args)
+	if inf, ok := e.infinity(err); ok {
+		v, err = inf, nil
+	}
+	if err != nil {
+		terr := e.errorf(call.Pos(), "%s: %v", types.ExprString(call), err)
+		if errors.Is(err, ErrDivByZero) {
+			return nil, sentinelError{terr, ErrDivByZero}
+		}
+		return nil, terr
+	}
+	return e.bind(call, types.TypeAndValue{Type: untyped(v), Value: v}), nil
+}
+
+// memo returns the identifier of the result of 'call', if an identical call
+// has already been computed in this evaluation: "sqrt(x) + sqrt(x)*2"
+// computes sqrt(x) once.
+//
+// Calls are identical if their source is, once their arguments have been
+// folded. ExprString elides the content of composite literals, those
+// calls are never memoized.
+func (e *evaluation) memo(call *ast.CallExpr) (*ast.Ident, bool) {
+	if !e.private {
+		return nil, false
+	}
+	name := types.ExprString(call)
+	if strings.Contains(name, "…") {
+		return nil, false
+	}
+	if _, ok := e.pkg.Scope().Lookup(name).(*types.Const); !ok {
+		return nil, false
+	}
+	return &ast.Ident{NamePos: call.Pos(), Name: name}, true
+}
+
+// builtin returns the name and the builtin function called by 'fun', if
+// any.
+func (e *evaluation) builtin(fun ast.Expr) (string, builtin, bool) {
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		b, ok := builtins[fun.Name]
+		return fun.Name, b, ok
+	case *ast.SelectorExpr:
+		if !e.std {
+			break
+		}
+		b, ok := e.stdFunc(fun)
+		return types.ExprString(fun), b, ok
+	}
+	return "", builtin{}, false
+}
+
+// toFloat64 converts 'v' to the nearest float64, if it is a real number
+// within the range of float64.
+func toFloat64(v constant.Value) (float64, error) {
+	fv := constant.ToFloat(v)
+	if fv.Kind() == constant.Unknown {
+		return 0, fmt.Errorf("%v is not a real number", v)
+	}
+	f, _ := constant.Float64Val(fv)
+	if math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%v overflows float64", v)
+	}
+	return f, nil
+}
+
+// realArgs checks that all 'args' are real numbers.
+func realArgs(args []constant.Value) error {
+	for _, arg := range args {
+		if constant.ToFloat(arg).Kind() == constant.Unknown {
+			return fmt.Errorf("%v is not a real number", arg)
+		}
+	}
+	return nil
+}
+
+// numericArgs checks that all 'args' are numbers, possibly complex.
+func numericArgs(args []constant.Value) error {
+	for _, arg := range args {
+		if constant.ToComplex(arg).Kind() == constant.Unknown {
+			return fmt.Errorf("%v is not a number", arg)
+		}
+	}
+	return nil
+}
+
+// fromFloat64 converts the result of a float64 computation into a constant.
+func fromFloat64(f float64) (constant.Value, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, nonFinite(f)
+	}
+	return constant.MakeFloat64(f), nil
+}
+
+// nonFinite is the error of a float64 computation resulting in an infinity
+// or NaN.
+type nonFinite float64
+
+func (f nonFinite) Error() string { return fmt.Sprintf("result is not finite: %v", float64(f)) }
+
+// callBig calls 'fn' at precision 'prec' with 'args'.
+func callBig(fn func(uint, []*big.Float) (*big.Float, error), prec uint, args []constant.Value) (constant.Value, error) {
+	fargs := make([]*big.Float, len(args))
+	for i, arg := range args {
+		r, ok := toRat(arg)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a real number", arg)
+		}
+		fargs[i] = new(big.Float).SetPrec(prec).SetRat(r)
+	}
+	f, err := fn(prec, fargs)
+	if err != nil {
+		return nil, err
+	}
+	return constant.Make(f), nil
+}
+
+// sqrt(x) is math.Sqrt(x).
+func sqrt(args []constant.Value) (constant.Value, error) {
+	x, err := toFloat64(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if x < 0 {
+		return nil, fmt.Errorf("negative argument %v", args[0])
+	}
+	return fromFloat64(math.Sqrt(x))
+}
+
+func bigSqrt(prec uint, args []*big.Float) (*big.Float, error) {
+	if args[0].Sign() < 0 {
+		return nil, fmt.Errorf("negative argument %v", args[0])
+	}
+	return new(big.Float).SetPrec(prec).Sqrt(args[0]), nil
+}
+
+// hypot(p, q) is math.Hypot(p, q).
+func hypot(args []constant.Value) (constant.Value, error) {
+	p, err := toFloat64(args[0])
+	if err != nil {
+		return nil, err
+	}
+	q, err := toFloat64(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return fromFloat64(math.Hypot(p, q))
+}
+
+// dist(x1, y1, x2, y2) is the euclidean distance between two points.
+func dist(args []constant.Value) (constant.Value, error) {
+	if err := realArgs(args); err != nil {
+		return nil, err
+	}
+	// Differences are exact.
+	dx := constant.BinaryOp(args[2], token.SUB, args[0])
+	dy := constant.BinaryOp(args[3], token.SUB, args[1])
+	return hypot([]constant.Value{dx, dy})
+}
+
+func bigHypot(prec uint, args []*big.Float) (*big.Float, error) {
+	// There is no overflow with big.Float, the naive formula is fine.
+	p := new(big.Float).SetPrec(prec).Mul(args[0], args[0])
+	q := new(big.Float).SetPrec(prec).Mul(args[1], args[1])
+	return bigSqrt(prec, []*big.Float{p.Add(p, q)})
+}
+
+func bigDist(prec uint, args []*big.Float) (*big.Float, error) {
+	dx := new(big.Float).SetPrec(prec).Sub(args[2], args[0])
+	dy := new(big.Float).SetPrec(prec).Sub(args[3], args[1])
+	return bigHypot(prec, []*big.Float{dx, dy})
+}
+
+// percentof(p, x) is p percent of x, computed exactly.
+func percentof(args []constant.Value) (constant.Value, error) {
+	if err := realArgs(args); err != nil {
+		return nil, err
+	}
+	v := constant.BinaryOp(args[0], token.MUL, args[1])
+	return constant.BinaryOp(v, token.QUO, constant.MakeInt64(100)), nil
+}
+
+// pctchange(from, to) is the relative change from 'from' to 'to', computed
+// exactly. It is a division by zero if 'from' is zero.
+func pctchange(args []constant.Value) (constant.Value, error) {
+	if err := realArgs(args); err != nil {
+		return nil, err
+	}
+	if constant.Sign(args[0]) == 0 {
+		return nil, ErrDivByZero
+	}
+	v := constant.BinaryOp(args[1], token.SUB, args[0])
+	return constant.BinaryOp(v, token.QUO, args[0]), nil
+}
+
+// maxPowBits is the maximum size of the numerator and denominator of an
+// exact power, larger powers are computed in float64.
+const maxPowBits = 4096
+
+// pow(x, y) is x to the power y. It is exact if y is an integer.
+func pow(args []constant.Value) (constant.Value, error) {
+	if err := realArgs(args); err != nil {
+		return nil, err
+	}
+	x, y := args[0], args[1]
+	if !powFloat(args) {
+		n, _ := constant.Int64Val(constant.ToInt(y))
+		if constant.Sign(x) == 0 && n < 0 {
+			return nil, ErrDivByZero
+		}
+		r, _ := toRat(x)
+		exp := big.NewInt(max(n, -n))
+		num := new(big.Int).Exp(r.Num(), exp, nil)
+		den := new(big.Int).Exp(r.Denom(), exp, nil)
+		if n < 0 {
+			num, den = den, num
+		}
+		if x.Kind() == constant.Int && n >= 0 {
+			return constant.Make(num), nil
+		}
+		return constant.Make(new(big.Rat).SetFrac(num, den)), nil
+	}
+	fx, err := toFloat64(x)
+	if err != nil {
+		return nil, err
+	}
+	fy, err := toFloat64(y)
+	if err != nil {
+		return nil, err
+	}
+	return fromFloat64(math.Pow(fx, fy))
+}
+
+// powFloat reports whether pow computes in float64: when 'y' is not an
+// integer, or when the exact power would be too large.
+func powFloat(args []constant.Value) bool {
+	r, ok := toRat(args[0])
+	if !ok {
+		return true
+	}
+	n, ok := constant.Int64Val(constant.ToInt(args[1]))
+	if !ok || n < -maxPowBits || n > maxPowBits {
+		return true
+	}
+	bits := max(r.Num().BitLen(), r.Denom().BitLen())
+	return int64(bits)*max(n, -n) > maxPowBits
+}
+
+// lookup(key, k1, v1, k2, v2, ..., [default]) is the value vi of the first
+// key ki equal to 'key', or the default value if none matches.
+func lookup(args []constant.Value) (constant.Value, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("expects at least 2 arguments, got %d", len(args))
+	}
+	key, pairs := args[0], args[1:]
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if equalValues(key, pairs[i]) {
+			return pairs[i+1], nil
+		}
+	}
+	if len(pairs)%2 == 1 {
+		return pairs[len(pairs)-1], nil
+	}
+	return nil, fmt.Errorf("no value for key %v", key)
+}
+
+// in(x, a, b, ...) reports whether x is equal to any of a, b, ...
+//
+// Numbers of different kinds are compared like in Go, but comparing a
+// number to a string or a bool is an error.
+func in(args []constant.Value) (constant.Value, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("expects at least 2 arguments, got %d", len(args))
+	}
+	x := args[0]
+	found := false
+	for _, a := range args[1:] {
+		if x.Kind() != a.Kind() && numericArgs([]constant.Value{x, a}) != nil {
+			return nil, fmt.Errorf("cannot compare %v and %v", x, a)
+		}
+		found = found || equalValues(x, a)
+	}
+	return constant.MakeBool(found), nil
+}
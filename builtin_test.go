@@ -0,0 +1,218 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHypot(t *testing.T) {
+	var c Scope
+	c.Assign("x", "3")
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"hypot(3, 4)", 5},
+		{"hypot(x, 4)", 5},
+		{"hypot(-3, -4)", 5},
+		{"hypot(hypot(3, 4), 12)", 13},
+		{"2*hypot(3, 4) + 1", 11},
+		{"hypot(3e200, 4e200)", 5e200},    // naive squaring overflows.
+		{"hypot(3e-200, 4e-200)", 5e-200}, // naive squaring underflows.
+		{"hypot(1e308, 1e308)", 1e308 * math.Sqrt2},
+		{"dist(0, 0, 3, 4)", 5},
+		{"dist(1, 1, 4, 5)", 5},
+		{"dist(1e300, 0, -2e300, 4e300)", 5e300},
+	}
+	for _, test := range tests {
+		got, err := c.Float64(test.expr)
+		if err != nil {
+			t.Errorf("Float64(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-15*test.want {
+			t.Errorf("Float64(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{"hypot(3)", "hypot(3, 4, 5)", "hypot(1i, 2)", `hypot("a", 2)`, "hypot(1e400, 1)", "dist(0, 0, 1, true)", "hypot(y, 1)"} {
+		if _, err := c.Float64(expr); err == nil {
+			t.Errorf("Float64(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestSqrt(t *testing.T) {
+	if got, err := (Scope{}).Float64("sqrt(2)"); err != nil || got != math.Sqrt2 {
+		t.Errorf("Float64(%q) = %v, %v, want %v", "sqrt(2)", got, err, math.Sqrt2)
+	}
+	for _, expr := range []string{"sqrt(-1)", "sqrt(1i)", "sqrt()"} {
+		if _, err := (Scope{}).Float64(expr); err == nil {
+			t.Errorf("Float64(%q) expected an error", expr)
+		}
+		if _, err := (Scope{}).BigFloat(expr, 100); err == nil {
+			t.Errorf("BigFloat(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"percentof(25, 200)", "50"},
+		{"percentof(10, 1/3.)", "1/30"},
+		{"percentof(-5, 40)", "-2"},
+		{"pctchange(100, 150)", "1/2"},
+		{"pctchange(150, 100)", "-1/3"},
+		{"pctchange(-2, 2)", "-2"},
+		{"pctchange(3, 3)", "0"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Eval(test.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got.ExactString() != test.want {
+			t.Errorf("Eval(%q) = %s, want %s", test.expr, got.ExactString(), test.want)
+		}
+	}
+
+	if _, err := (Scope{}).Eval("pctchange(0, 5)"); !errors.Is(err, ErrDivByZero) {
+		t.Errorf("Eval(%q) = %v, want ErrDivByZero", "pctchange(0, 5)", err)
+	}
+	if _, err := (Scope{}).Eval(`percentof("a", 5)`); err == nil {
+		t.Errorf("Eval(%q) expected an error", `percentof("a", 5)`)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	var c Scope
+	c.Assign("key", `"b"`)
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`lookup(key, "a", 1, "b", 2, 3)`, "2"},
+		{`lookup("z", "a", 1, "b", 2, 3)`, "3"},
+		{`lookup("a", "a", 1, "a", 2)`, "1"},
+		{`lookup(2, 1, "one", 2.0, "two")`, `"two"`},
+		{`lookup(1, "1", "string", 1, "int")`, `"int"`},
+		{`lookup(1, "default")`, `"default"`},
+		{`lookup(key, "b", 1.5) * 2`, "3"},
+	}
+	for _, test := range tests {
+		got, err := c.Eval(test.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got.ExactString() != test.want {
+			t.Errorf("Eval(%q) = %s, want %s", test.expr, got.ExactString(), test.want)
+		}
+	}
+
+	for _, expr := range []string{`lookup("z", "a", 1)`, "lookup(1)", "lookup()"} {
+		if _, err := c.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestIn(t *testing.T) {
+	var c Scope
+	c.Assign("status", "2")
+	c.Assign("name", `"bob"`)
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"in(status, 1, 2, 5)", true},
+		{"in(status, 1, 3)", false},
+		{"in(status, 1.5, 2.0)", true},
+		{"in(2i, 1, 2i)", true},
+		{`in(name, "alice", "bob")`, true},
+		{"in(true, false)", false},
+		{"in(status, 1) || in(status, 2)", true},
+	}
+	for _, test := range tests {
+		got, err := c.Bool(test.expr)
+		if err != nil {
+			t.Errorf("Bool(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Bool(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{`in(status, 1, "2")`, "in(true, 1)", "in(status)", "in()"} {
+		if _, err := c.Bool(expr); err == nil {
+			t.Errorf("Bool(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestMemoization(t *testing.T) {
+	calls := 0
+	builtins["count"] = builtin{nargs: 1, fn: func(args []constant.Value) (constant.Value, error) {
+		calls++
+		return args[0], nil
+	}}
+	defer delete(builtins, "count")
+
+	var c Scope
+	c.Assign("x", "2")
+	c.DefineFunc("twice", []string{"v"}, "count(v) + count(v)")
+	tests := []struct {
+		expr  string
+		want  int64
+		calls int
+	}{
+		{"count(x) + count(x)*2", 6, 1},
+		{"count(x) + count(x+1) + count(x)", 7, 2},
+		{"count(count(x)) + count(count(x))", 4, 2},
+		{"twice(x) + count(x)", 6, 1},
+		{"twice(1) + twice(2)", 6, 2},
+	}
+	for _, test := range tests {
+		calls = 0
+		got, err := c.Int(test.expr)
+		if err != nil {
+			t.Errorf("Int(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want || calls != test.calls {
+			t.Errorf("Int(%q) = %v in %d calls, want %v in %d calls", test.expr, got, calls, test.want, test.calls)
+		}
+	}
+}
+
+// BenchmarkMemoization evaluates a deliberately redundant expression, where
+// the same expensive call is repeated, and the same expression without
+// repetitions.
+func BenchmarkMemoization(b *testing.B) {
+	redundant := strings.Repeat("sqrt(2) + ", 20) + "0"
+	distinct := ""
+	for i := range 20 {
+		distinct += fmt.Sprintf("sqrt(%d) + ", i+2)
+	}
+	distinct += "0"
+	for _, bench := range []struct{ name, expr string }{
+		{"redundant", redundant},
+		{"distinct", distinct},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for range b.N {
+				if _, err := (Scope{}).BigFloat(bench.expr, 20000); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
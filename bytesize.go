@@ -0,0 +1,74 @@
+package calc
+
+import (
+	"go/token"
+	"strings"
+)
+
+// byteUnits are the byte size suffixes, and their number of bytes.
+var byteUnits = map[string]string{
+	"B":   "1",
+	"KB":  "1e3",
+	"kB":  "1e3",
+	"MB":  "1e6",
+	"GB":  "1e9",
+	"TB":  "1e12",
+	"KiB": "1<<10",
+	"MiB": "1<<20",
+	"GiB": "1<<30",
+	"TiB": "1<<40",
+}
+
+// ByteSize computes the byte size expression, see [Scope.ByteSize].
+func ByteSize(expr string) (int64, error) { return Scope{}.ByteSize(expr) }
+
+// ByteSize evaluates 'expr' as a number of bytes, where numbers can have a
+// byte size suffix: "2GiB + 512MiB" is 2684354560.
+//
+// Valid suffixes are:
+//
+//	B    1
+//	KB   1000 (kB is also accepted)
+//	MB   1000**2
+//	GB   1000**3
+//	TB   1000**4
+//	KiB  1024
+//	MiB  1024**2
+//	GiB  1024**3
+//	TiB  1024**4
+//
+// The suffix must immediately follow a decimal number, "2 GB" is the
+// variable 'GB' after a 2. Numbers without suffix are bytes. Suffixed
+// numbers are computed exactly, "1.5KiB" is 1536, but the result must be a
+// whole number of bytes.
+//
+// There is no PB or PiB: Go reads "1P" as a number with a malformed
+// exponent.
+func (s Scope) ByteSize(expr string) (int64, error) {
+	s.byteSizes = true
+	return s.Int(expr)
+}
+
+// rewriteByteSizes rewrites byte size suffixed numbers in 'expr' into
+// scaled literals: "2KiB" becomes "(2*(1<<10))".
+func rewriteByteSizes(expr string) string {
+	lexemes := scan(expr)
+	var b strings.Builder
+	last := 0
+	for i := 0; i+1 < len(lexemes); i++ {
+		num, suffix := lexemes[i], lexemes[i+1]
+		if num.tok != token.INT && num.tok != token.FLOAT || suffix.tok != token.IDENT || suffix.off != num.end || !isDecimal(num.lit) {
+			continue
+		}
+		size, ok := byteUnits[suffix.lit]
+		if !ok {
+			continue
+		}
+		b.WriteString(expr[last:num.off])
+		b.WriteString("(" + num.lit + "*(" + size + "))")
+		last = suffix.end
+		i++
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package calc
+
+import "testing"
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int64
+	}{
+		{"2GiB + 512MiB", 2684354560},
+		{"1KB + 1kB + 1B", 2001},
+		{"1.5KiB", 1536},
+		{"0.1KB", 100},
+		{"3MB / 1000", 3000},
+		{"1TiB / 1GiB", 1024},
+		{"1000TB", 1e15},
+		{"42", 42},
+		{"2KiB*2", 4096},
+	}
+	for _, test := range tests {
+		got, err := ByteSize(test.expr)
+		if err != nil {
+			t.Errorf("ByteSize(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ByteSize(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"1.5B", "2 GB", "0x10KB", "1KIB", "2Gib"} {
+		if _, err := ByteSize(expr); err == nil {
+			t.Errorf("ByteSize(%q) expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package calc
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// WithCaseInsensitive returns a copy of s where identifiers are case
+// insensitive, like in spreadsheets: "PI", "Pi" and "pi" all refer to the
+// variable 'pi'. This applies to variables, imports and their exported
+// variables, and to functions, user-defined or builtin: "SQRT(2)" is
+// "sqrt(2)".
+//
+// An identifier defined with the exact case written always wins. Otherwise,
+// if several definitions only differ by their case, say 'pi' and 'PI', then
+// "Pi" is ambiguous, and reported as an error.
+func (s Scope) WithCaseInsensitive() Scope {
+	s.caseless = true
+	return s
+}
+
+// canonical rewrites the identifiers of 'x' into the case of their
+// definition. Only the node itself is rewritten, not its children, and new
+// nodes are returned, so that 'x' is never changed.
+func (s Scope) canonical(e *evaluation, x ast.Expr) (ast.Expr, error) {
+	switch n := x.(type) {
+	case *ast.Ident:
+		name, err := s.fold(e, n, s.names(e.pkg))
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Ident{NamePos: n.NamePos, Name: name}, nil
+	case *ast.CallExpr:
+		fun, err := s.canonical(e, n.Fun)
+		if err != nil {
+			return nil, err
+		}
+		c := *n
+		c.Fun = fun
+		return &c, nil
+	case *ast.SelectorExpr:
+		id, ok := n.X.(*ast.Ident)
+		if !ok {
+			return x, nil
+		}
+		pkg, err := s.canonical(e, id)
+		if err != nil {
+			return nil, err
+		}
+		var imported *types.Package
+		if e.pkg != nil {
+			if obj, ok := e.pkg.Scope().Lookup(pkg.(*ast.Ident).Name).(*types.PkgName); ok {
+				imported = obj.Imported()
+			}
+		}
+		if imported == nil {
+			return &ast.SelectorExpr{X: pkg, Sel: n.Sel}, nil
+		}
+		sel, err := s.fold(e, n.Sel, imported.Scope().Names())
+		if err != nil {
+			return nil, err
+		}
+		return &ast.SelectorExpr{X: pkg, Sel: &ast.Ident{NamePos: n.Sel.NamePos, Name: sel}}, nil
+	}
+	return x, nil
+}
+
+// names returns the identifiers that can be used in expressions evaluated
+// in 'p'.
+func (s Scope) names(p *types.Package) []string {
+	names := types.Universe.Names()
+	if p != nil {
+		names = append(names, p.Scope().Names()...)
+	}
+	for name := range builtins {
+		names = append(names, name)
+	}
+	for name := range s.funcs {
+		names = append(names, name)
+	}
+	return names
+}
+
+// fold returns the name among 'names' matching 'id' case insensitively.
+func (s Scope) fold(e *evaluation, id *ast.Ident, names []string) (string, error) {
+	var matches []string
+	for _, name := range names {
+		if name == id.Name {
+			return name, nil
+		}
+		if strings.EqualFold(name, id.Name) && !slices.Contains(matches, name) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return id.Name, nil // reported as undefined.
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", e.errorf(id.Pos(), "ambiguous identifier %s: %s", id.Name, strings.Join(matches, ", "))
+}
@@ -0,0 +1,53 @@
+package calc
+
+import "testing"
+
+func TestWithCaseInsensitive(t *testing.T) {
+	var lib Scope
+	lib.Assign("Kilo", "1000")
+	var c Scope
+	c.Assign("pi", "3")
+	c.Assign("rate", "2")
+	c.Assign("Rate", "5")
+	c.Assign("RATE", "7")
+	c.Import("units", &lib)
+	c.DefineFunc("double", []string{"x"}, "2*x")
+	c = c.WithCaseInsensitive().WithMathNamespace()
+
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"pi", 3},
+		{"PI", 3},
+		{"Pi", 3},
+		{"rate", 2}, // exact matches win.
+		{"Rate", 5},
+		{"SQRT(16)", 4},
+		{"Double(PI)", 6},
+		{"UNITS.KILO", 1000},
+		{"Units.kilo / 10", 100},
+		{"MATH.MAXINT8", 127},
+		{"LEN(\"abc\")", 3},
+	}
+	for _, test := range tests {
+		got, err := c.Float64(test.expr)
+		if err != nil {
+			t.Errorf("Float64(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Float64(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	if _, err := c.Float64("rAtE"); err == nil || err.Error() != "eval:1:1: ambiguous identifier rAtE: RATE, Rate, rate" {
+		t.Errorf("Float64(%q) error = %v, want an ambiguity error", "rAtE", err)
+	}
+	if _, err := c.Float64("unknown"); err == nil {
+		t.Errorf("Float64(%q) expected an error", "unknown")
+	}
+	if _, err := (Scope{}).Float64("SQRT(4)"); err == nil {
+		t.Errorf("Float64(%q) expected an error without the option", "SQRT(4)")
+	}
+}
@@ -0,0 +1,83 @@
+package calc
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// WithChainedComparisons returns a copy of s where comparisons can be
+// chained like in Python or in mathematics: "1 < x < 10" means
+// "1 < x && x < 10".
+//
+// A chain is a sequence of comparisons not separated by parentheses. It is
+// true if every comparison in it is true, each operand being compared to
+// its neighbors, from left to right. Go constants have no side effects, so
+// each operand is evaluated once as far as the result is concerned.
+//
+// Only ordering operators (<, <=, >, >=) can be chained. In Go,
+// "a == b == c" is already valid for booleans, and means "(a == b) == c".
+// To avoid any ambiguity, chains containing == or != are rejected with an
+// error. Parentheses restore Go's meaning: "(a == b) == c".
+func (s Scope) WithChainedComparisons() Scope {
+	s.chain = true
+	return s
+}
+
+// unchain rewrites a chain of comparisons rooted in x into a conjunction.
+func unchain(e *evaluation, x ast.Expr) (ast.Expr, error) {
+	if !isChain(x) {
+		return x, nil
+	}
+	// Go parses "a < b < c" as "(a < b) < c": operands are collected from
+	// right to left.
+	var (
+		operands []ast.Expr
+		links    []*ast.BinaryExpr
+	)
+	for cur := x.(*ast.BinaryExpr); ; {
+		if cur.Op == token.EQL || cur.Op == token.NEQ {
+			return nil, e.errorf(cur.OpPos, "ambiguous chained comparison: %v cannot be chained, use parentheses", cur.Op)
+		}
+		links = append([]*ast.BinaryExpr{cur}, links...)
+		operands = append([]ast.Expr{cur.Y}, operands...)
+		inner, ok := cur.X.(*ast.BinaryExpr)
+		if !ok || !isComparison(inner.Op) {
+			operands = append([]ast.Expr{cur.X}, operands...)
+			break
+		}
+		cur = inner
+	}
+
+	var conj ast.Expr
+	for i, link := range links {
+		cmp := &ast.BinaryExpr{X: operands[i], OpPos: link.OpPos, Op: link.Op, Y: operands[i+1]}
+		if conj == nil {
+			conj = cmp
+			continue
+		}
+		// The operand is already the right-hand side of the previous
+		// comparison: each node of the tree is transformed once.
+		cmp.X = copyExpr(operands[i])
+		conj = &ast.BinaryExpr{X: conj, OpPos: link.OpPos, Op: token.LAND, Y: cmp}
+	}
+	return conj, nil
+}
+
+// isChain reports whether x is a comparison whose left operand is itself an
+// unparenthesized comparison.
+func isChain(x ast.Expr) bool {
+	b, ok := x.(*ast.BinaryExpr)
+	if !ok || !isComparison(b.Op) {
+		return false
+	}
+	inner, ok := b.X.(*ast.BinaryExpr)
+	return ok && isComparison(inner.Op)
+}
+
+func isComparison(op token.Token) bool {
+	switch op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return true
+	}
+	return false
+}
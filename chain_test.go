@@ -0,0 +1,58 @@
+package calc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithChainedComparisons(t *testing.T) {
+	var c Scope
+	c.Assign("x", "5")
+	c = c.WithChainedComparisons()
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"1 < x < 10", true},
+		{"1 < x < 3", false},
+		{"10 > x >= 5 > 0", true},
+		{"10 > x >= 5 > 5", false},
+		{"1 < x && x < 10", true},
+		{"(1 < x) == true", true},
+		{"0 < x < 10 && 4 < x <= 5", true},
+	}
+	for _, test := range tests {
+		got, err := c.Bool(test.expr)
+		if err != nil {
+			t.Errorf("Bool(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Bool(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{"1 < x == true", "true == false == false"} {
+		if _, err := c.Bool(expr); err == nil {
+			t.Errorf("Bool(%q) expected an error", expr)
+		}
+	}
+	if _, err := c.Bool("1 < x == true"); err == nil || !strings.Contains(err.Error(), ":1:7:") {
+		t.Errorf("Bool(%q) error = %v, want a position", "1 < x == true", err)
+	}
+
+	// Middle operands are transformed once.
+	for _, test := range []struct {
+		s    Scope
+		expr string
+	}{
+		{c.WithModulus(7), "1 < x-3 < 5"},
+		{c.WithModulus(7), "0 <= x+3 < 2 < 3"},
+		{c.WithDivMode(DivFloor), "-4 < -x/2 < -2"},
+	} {
+		if got, err := test.s.Bool(test.expr); err != nil || !got {
+			t.Errorf("Bool(%q) = %v, %v, want true", test.expr, got, err)
+		}
+	}
+}
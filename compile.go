@@ -0,0 +1,234 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// Compiled is an expression parsed once, and evaluated many times.
+//
+// A Compiled is safe for concurrent use: evaluations work on their own
+// copy of the syntax tree.
+type Compiled struct {
+	s    Scope
+	expr string   // as written.
+	src  string   // rewritten.
+	x    ast.Expr // parsed from src, never rewritten.
+}
+
+// Compile parses 'expr' to be evaluated later in s, with the options of s.
+//
+// Syntax errors are reported by Compile, other errors by the evaluation.
+// Variables are resolved at evaluation time: changes to the variables of
+// s are visible to the compiled expression.
+func (s Scope) Compile(expr string) (*Compiled, error) {
+	src, err := s.rewrite(expr)
+	if err != nil {
+		return nil, err
+	}
+	x, err := s.newEvaluation().parse(expr, src)
+	if err != nil {
+		return nil, err
+	}
+	return &Compiled{s: s, expr: expr, src: src, x: x}, nil
+}
+
+// CompileAll compiles each of 'exprs' like [Scope.Compile], for instance
+// to evaluate many metrics repeatedly.
+//
+// The result always has one element per expression. The elements of
+// expressions that fail are nil, and their errors are joined in the
+// returned error, with the index of their expression.
+func (s Scope) CompileAll(exprs []string) ([]*Compiled, error) {
+	all := make([]*Compiled, len(exprs))
+	var errs []error
+	for i, expr := range exprs {
+		c, err := s.Compile(expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("expression %d: %w", i, err))
+			continue
+		}
+		all[i] = c
+	}
+	return all, errors.Join(errs...)
+}
+
+// String returns the expression, as written.
+func (c *Compiled) String() string { return c.expr }
+
+// Eval evaluates the compiled expression.
+func (c *Compiled) Eval() (constant.Value, error) {
+	tv, err := c.check(c.s)
+	if err != nil {
+		return nil, err
+	}
+	return tv.Value, nil
+}
+
+// check evaluates the compiled expression in 's'.
+func (c *Compiled) check(s Scope) (tv types.TypeAndValue, err error) {
+	defer recoverPanic(&err, c.expr)
+	if s.budget != nil {
+		end, err := s.budget.begin(c.expr)
+		if err != nil {
+			return types.TypeAndValue{}, err
+		}
+		defer end()
+	}
+	e := s.newEvaluation()
+	e.addFile(c.expr, c.src)
+	x, err := s.transform(e, copyExpr(c.x))
+	if err != nil {
+		return types.TypeAndValue{}, err
+	}
+	return e.checkConst(x)
+}
+
+// Match evaluates the compiled boolean expression, where the fields of
+// 'record' are available as variables, assigned like [Scope.AssignValue]
+// does: so "age >= 18 && country == \"FR\"" can filter records.
+//
+// Fields take precedence over the variables of the scope with the same
+// name. A missing field is an "undefined" error, unless the scope has a
+// variable of the same name, which is then a default value. Fields of
+// types unsupported by AssignValue are errors.
+func (c *Compiled) Match(record map[string]any) (bool, error) {
+	s, err := c.bind(record)
+	if err != nil {
+		return false, err
+	}
+	tv, err := c.check(s)
+	if err != nil {
+		return false, err
+	}
+	if tv.Value.Kind() != constant.Bool {
+		return false, fmt.Errorf("not representable as a bool (%v): %q", tv.Value.Kind(), c.expr)
+	}
+	return constant.BoolVal(tv.Value), nil
+}
+
+// MatchVerbose is like [Compiled.Match], but it also explains the result:
+// it returns the conditions of the expression, the operands of its && and
+// || operators, in the order they are evaluated, each followed by its
+// value, like "age >= 18: true".
+//
+// Like in Go, evaluation stops as soon as the result is known: in "a || b",
+// 'b' is not listed if 'a' is true.
+func (c *Compiled) MatchVerbose(record map[string]any) (bool, []string, error) {
+	s, err := c.bind(record)
+	if err != nil {
+		return false, nil, err
+	}
+	// The whole expression first, so that all errors are reported, even
+	// in conditions that are not evaluated.
+	tv, err := c.check(s)
+	if err != nil {
+		return false, nil, err
+	}
+	if tv.Value.Kind() != constant.Bool {
+		return false, nil, fmt.Errorf("not representable as a bool (%v): %q", tv.Value.Kind(), c.expr)
+	}
+	var log []string
+	ok, err := c.conditions(s, c.x, &log)
+	if err != nil {
+		return false, nil, err
+	}
+	return ok, log, nil
+}
+
+// conditions evaluates the boolean expression 'x', a part of the compiled
+// expression, in 's', and appends its conditions to 'log'.
+func (c *Compiled) conditions(s Scope, x ast.Expr, log *[]string) (bool, error) {
+	switch n := x.(type) {
+	case *ast.ParenExpr:
+		return c.conditions(s, n.X, log)
+	case *ast.BinaryExpr:
+		if n.Op == token.LAND || n.Op == token.LOR {
+			ok, err := c.conditions(s, n.X, log)
+			if err != nil || ok == (n.Op == token.LOR) {
+				return ok, err
+			}
+			return c.conditions(s, n.Y, log)
+		}
+	}
+	part := &Compiled{s: s, expr: c.expr, src: c.src, x: x}
+	tv, err := part.check(s)
+	if err != nil {
+		return false, err
+	}
+	ok := constant.BoolVal(tv.Value)
+	*log = append(*log, fmt.Sprintf("%s: %v", s.exprString(x), ok))
+	return ok, nil
+}
+
+// Vector evaluates 'expr' element-wise, where the variables 'vars' are
+// vectors of equal length: the i-th element of the result is 'expr'
+// evaluated with each variable set to its i-th element. So "a + b*2", with
+// a = [1, 2] and b = [3, 4], is [7, 10].
+//
+// Like in [Compiled.Match], vectors take precedence over the variables of
+// s with the same name, and other variables of s are available as scalars.
+// An error is returned if vectors have different lengths, or if there is
+// no vector at all.
+func (s Scope) Vector(expr string, vars map[string][]float64) ([]float64, error) {
+	n := -1
+	for name, v := range vars {
+		if n >= 0 && len(v) != n {
+			return nil, fmt.Errorf("vector %s has %d elements, want %d", name, len(v), n)
+		}
+		n = len(v)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("no vector to evaluate %q", expr)
+	}
+	c, err := s.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]float64, n)
+	record := make(map[string]any, len(vars))
+	for i := range res {
+		for name, v := range vars {
+			record[name] = v[i]
+		}
+		s, err := c.bind(record)
+		if err != nil {
+			return nil, err
+		}
+		tv, err := c.check(s)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		f := constant.ToFloat(tv.Value)
+		if f.Kind() == constant.Unknown {
+			return nil, fmt.Errorf("element %d: not representable as a float (%v): %q", i, tv.Value.Kind(), expr)
+		}
+		res[i], _ = constant.Float64Val(f)
+	}
+	return res, nil
+}
+
+// bind returns a copy of the compiled scope, with the fields of 'record'
+// assigned.
+func (c *Compiled) bind(record map[string]any) (Scope, error) {
+	s := c.s.clone()
+	names := make([]string, 0, len(record))
+	for name := range record {
+		names = append(names, name)
+	}
+	sort.Strings(names) // for deterministic errors.
+	for _, name := range names {
+		val, t, ok := constantOf(record[name])
+		if !ok {
+			return Scope{}, fmt.Errorf("field %s: unsupported type %T", name, record[name])
+		}
+		s.Delete(name)
+		s.assign(name, types.TypeAndValue{Type: untypedOf(t), Value: val})
+	}
+	return s, nil
+}
@@ -0,0 +1,167 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCompiledMatch(t *testing.T) {
+	var s Scope
+	s.Assign("adult", "18")
+	c, err := s.Compile(`age >= adult && country == "FR"`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	tests := []struct {
+		record map[string]any
+		want   bool
+	}{
+		{map[string]any{"age": 20, "country": "FR"}, true},
+		{map[string]any{"age": 17, "country": "FR"}, false},
+		{map[string]any{"age": 20.5, "country": "US"}, false},
+		{map[string]any{"age": uint8(18), "country": "FR", "extra": true}, true},
+		{map[string]any{"age": 16, "adult": 16, "country": "FR"}, true},
+	}
+	for _, test := range tests {
+		got, err := c.Match(test.record)
+		if err != nil {
+			t.Errorf("Match(%v) unexpected error: %v", test.record, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Match(%v) = %v, want %v", test.record, got, test.want)
+		}
+	}
+
+	// Fields do not leak into the scope.
+	if _, ok := s.Get("age"); ok {
+		t.Errorf("Match() changed the scope")
+	}
+
+	for _, record := range []map[string]any{
+		{"age": 20},
+		{"age": "20", "country": "FR"},
+		{"age": []int{20}, "country": "FR"},
+	} {
+		if _, err := c.Match(record); err == nil {
+			t.Errorf("Match(%v) expected an error", record)
+		}
+	}
+
+	n, err := s.Compile("adult + 1")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	if _, err := n.Match(nil); err == nil || !strings.Contains(err.Error(), "not representable as a bool") {
+		t.Errorf("Match() error = %v, want a bool error", err)
+	}
+
+	if _, err := s.Compile("1 +"); err == nil {
+		t.Errorf("Compile(%q) expected an error", "1 +")
+	}
+}
+
+func TestCompileAll(t *testing.T) {
+	var s Scope
+	s.Assign("x", "2")
+	all, err := s.CompileAll([]string{"x + 1", "x *", "x * 10", ")"})
+	if err == nil || !strings.Contains(err.Error(), "expression 1:") || !strings.Contains(err.Error(), "expression 3:") {
+		t.Errorf("CompileAll() error = %v, want errors for expressions 1 and 3", err)
+	}
+	if len(all) != 4 || all[1] != nil || all[3] != nil {
+		t.Fatalf("CompileAll() = %v, want 4 elements, nil for failures", all)
+	}
+	for i, want := range map[int]int64{0: 3, 2: 20} {
+		v, err := all[i].Eval()
+		if err != nil || v.String() != fmt.Sprint(want) {
+			t.Errorf("CompileAll()[%d].Eval() = %v, %v, want %d", i, v, err, want)
+		}
+	}
+}
+
+func TestCompiledMatchVerbose(t *testing.T) {
+	c, err := Scope{}.Compile(`(age >= 18 && country == "FR") || !banned`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	tests := []struct {
+		record map[string]any
+		want   bool
+		log    []string
+	}{
+		{map[string]any{"age": 20, "country": "FR", "banned": true}, true, []string{"age >= 18: true", `country == "FR": true`}},
+		{map[string]any{"age": 17, "country": "FR", "banned": true}, false, []string{"age >= 18: false", "!banned: false"}},
+		{map[string]any{"age": 20, "country": "US", "banned": false}, true, []string{"age >= 18: true", `country == "FR": false`, "!banned: true"}},
+	}
+	for _, test := range tests {
+		got, log, err := c.MatchVerbose(test.record)
+		if err != nil {
+			t.Errorf("MatchVerbose(%v) unexpected error: %v", test.record, err)
+			continue
+		}
+		if got != test.want || !slices.Equal(log, test.log) {
+			t.Errorf("MatchVerbose(%v) = %v, %q, want %v, %q", test.record, got, log, test.want, test.log)
+		}
+	}
+	// Conditions that are not evaluated are still checked.
+	if _, _, err := c.MatchVerbose(map[string]any{"age": 20, "country": "FR", "banned": 1}); err == nil {
+		t.Errorf("MatchVerbose() with a non bool field expected an error")
+	}
+}
+
+func TestCompiledConcurrent(t *testing.T) {
+	var s Scope
+	s.DefineFunc("sq", []string{"x"}, "x*x")
+	c, err := s.WithChainedComparisons().Compile("0 <= sq(n) - sqrt(4) < 100")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.Match(map[string]any{"n": i % 20})
+			if want := i%20 >= 2 && i%20 < 11; err != nil || got != want {
+				t.Errorf("Match(n=%d) = %v, %v, want %v", i%20, got, err, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestVector(t *testing.T) {
+	var s Scope
+	s.Assign("k", "10")
+	got, err := s.Vector("a + b*2 + k", map[string][]float64{"a": {1, 2}, "b": {3, 4}})
+	if err != nil {
+		t.Fatalf("Vector() unexpected error: %v", err)
+	}
+	if !slices.Equal(got, []float64{17, 20}) {
+		t.Errorf("Vector() = %v, want [17 20]", got)
+	}
+	if got, err := s.Vector("k", map[string][]float64{"a": {}}); err != nil || len(got) != 0 {
+		t.Errorf("Vector() of empty vectors = %v, %v, want []", got, err)
+	}
+
+	tests := []struct {
+		expr string
+		vars map[string][]float64
+		err  string
+	}{
+		{"a + b", map[string][]float64{"a": {1, 2}, "b": {3}}, "elements"},
+		{"k", nil, "no vector"},
+		{"a / b", map[string][]float64{"a": {1, 2}, "b": {1, 0}}, "element 1: "},
+		{"a > 0", map[string][]float64{"a": {1}}, "element 0: not representable as a float"},
+		{"a +", map[string][]float64{"a": {1}}, "expected operand"},
+	}
+	for _, test := range tests {
+		_, err := s.Vector(test.expr, test.vars)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Vector(%q) error = %v, want %q", test.expr, err, test.err)
+		}
+	}
+}
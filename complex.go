@@ -0,0 +1,154 @@
+package calc
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"strings"
+)
+
+// Polar evaluates 'expr' as a complex128 and returns its polar form: the
+// magnitude and the phase, in radians in the range [-Pi, Pi].
+//
+// Real results have a phase of 0 (or Pi if negative), and pure imaginary
+// ones a phase of ±Pi/2.
+func (s Scope) Polar(expr string) (mag, phase float64, err error) {
+	c, err := s.Complex128(expr)
+	if err != nil {
+		return 0, 0, err
+	}
+	mag, phase = cmplx.Polar(c)
+	return mag, phase, nil
+}
+
+// DefaultRealTolerance is the tolerance of [Scope.RealIfClose] when it is
+// given a negative one: 100 times the machine epsilon of float64, about
+// 2.2e-14, like NumPy's real_if_close.
+const DefaultRealTolerance = 100 * 0x1p-52
+
+// RealIfClose evaluates 'expr' as a complex128, and returns its real part
+// if its imaginary part is negligible: at most 'tol' in absolute value. So
+// residues of complex computations, like "1 + 1e-17i", are real. Otherwise
+// an error is returned.
+//
+// A negative 'tol' is [DefaultRealTolerance], and a zero one only accepts
+// real results.
+func (s Scope) RealIfClose(expr string, tol float64) (float64, error) {
+	c, err := s.Complex128(expr)
+	if err != nil {
+		return 0, err
+	}
+	if tol < 0 {
+		tol = DefaultRealTolerance
+	}
+	if math.Abs(imag(c)) > tol {
+		return 0, fmt.Errorf("imaginary part %g exceeds tolerance %g: %q", imag(c), tol, expr)
+	}
+	return real(c), nil
+}
+
+// WithJImaginary returns a copy of s where imaginary numbers can be written
+// with a 'j' suffix, like electrical engineers do: "3 + 4j" is "3 + 4i".
+//
+// The 'j' is a suffix only if it immediately follows a number literal, and
+// ends it: "4j", "2.5j" and "1e3j" are imaginary, while in "4 j", "4*j" or
+// "j" the identifier 'j' is a variable, and "4jk" is an error, like "4ik".
+func (s Scope) WithJImaginary() Scope {
+	s.jimag = true
+	return s
+}
+
+// rewriteJImaginary replaces the 'j' suffix of imaginary literals in 'expr'
+// with Go's 'i'.
+func rewriteJImaginary(expr string) string {
+	lexemes := scan(expr)
+	b := []byte(expr)
+	for i := 0; i+1 < len(lexemes); i++ {
+		x, y := lexemes[i], lexemes[i+1]
+		if (x.tok == token.INT || x.tok == token.FLOAT) && y.tok == token.IDENT && y.lit == "j" && y.off == x.end {
+			b[y.off] = 'i'
+		}
+	}
+	return string(b)
+}
+
+// conj(z) is the complex conjugate of z.
+func conj(args []constant.Value) (constant.Value, error) {
+	if err := numericArgs(args); err != nil {
+		return nil, err
+	}
+	z := args[0]
+	if z.Kind() != constant.Complex {
+		return z, nil
+	}
+	return constant.BinaryOp(constant.Real(z), token.SUB, constant.MakeImag(constant.Imag(z))), nil
+}
+
+// re(z) is the real part of z.
+func re(args []constant.Value) (constant.Value, error) {
+	if err := numericArgs(args); err != nil {
+		return nil, err
+	}
+	return constant.Real(args[0]), nil
+}
+
+// im(z) is the imaginary part of z.
+func im(args []constant.Value) (constant.Value, error) {
+	if err := numericArgs(args); err != nil {
+		return nil, err
+	}
+	return constant.Imag(args[0]), nil
+}
+
+// abs(z) is the absolute value of z, its magnitude if it is complex.
+//
+// It is exact if z is real (or has a zero imaginary part), and computed in
+// float64 precision otherwise.
+func abs(args []constant.Value) (constant.Value, error) {
+	if err := numericArgs(args); err != nil {
+		return nil, err
+	}
+	z := args[0]
+	if constant.Sign(constant.Imag(z)) == 0 {
+		x := constant.Real(z)
+		if constant.Sign(x) < 0 {
+			x = constant.UnaryOp(token.SUB, x, 0)
+		}
+		return x, nil
+	}
+	return hypot([]constant.Value{constant.Real(z), constant.Imag(z)})
+}
+
+// absFloat reports whether abs computes in float64: when z is complex.
+func absFloat(args []constant.Value) bool {
+	return constant.Sign(constant.Imag(args[0])) != 0
+}
+
+// FormatComplex evaluates 'expr' as a complex128 and formats it as "a+bi",
+// where the real and imaginary parts are formatted like
+// [strconv.FormatFloat] does, with 'format' and 'prec'.
+//
+// Pure real results are formatted without imaginary part ("2"), and pure
+// imaginary ones without real part ("3i"). Zero is "0" formatted.
+func (s Scope) FormatComplex(expr string, format byte, prec int) (string, error) {
+	c, err := s.Complex128(expr)
+	if err != nil {
+		return "", err
+	}
+	r, i := real(c), imag(c)
+	re := strconv.FormatFloat(r, format, prec, 64)
+	if i == 0 {
+		return re, nil
+	}
+	im := strconv.FormatFloat(i, format, prec, 64) + "i"
+	if r == 0 {
+		return im, nil
+	}
+	if !strings.HasPrefix(im, "-") {
+		im = "+" + im
+	}
+	return re + im, nil
+}
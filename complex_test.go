@@ -0,0 +1,108 @@
+package calc
+
+import "testing"
+
+func TestComplexBuiltins(t *testing.T) {
+	tests := []struct {
+		expr string
+		want complex128
+	}{
+		{"conj(2+3i)", 2 - 3i},
+		{"conj(2)", 2},
+		{"conj(-3i)", 3i},
+		{"re(2+3i)", 2},
+		{"im(2+3i)", 3},
+		{"re(5)", 5},
+		{"im(5)", 0},
+		{"abs(3+4i)", 5},
+		{"abs(-3+0i)", 3},
+		{"abs(-4i)", 4},
+		{"abs(-2.5)", 2.5},
+		{"conj(1+1i) * (1+1i)", 2},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Complex128(test.expr)
+		if err != nil {
+			t.Errorf("Complex128(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Complex128(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	// abs of a real is exact.
+	if v, err := (Scope{}).Eval("abs(-1/3.)"); err != nil || v.ExactString() != "1/3" {
+		t.Errorf("Eval(%q) = %v, %v, want 1/3", "abs(-1/3.)", v, err)
+	}
+	for _, expr := range []string{`conj("a")`, "re(true)", "abs()"} {
+		if _, err := (Scope{}).Eval(expr); err == nil {
+			t.Errorf("Eval(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestWithJImaginary(t *testing.T) {
+	var c Scope
+	c.Assign("j", "2")
+	s := c.WithJImaginary()
+	tests := []struct {
+		expr string
+		want complex128
+	}{
+		{"3 + 4j", 3 + 4i},
+		{"2.5j", 2.5i},
+		{"1e1j", 10i},
+		{"4i", 4i},
+		{"j", 2},
+		{"4*j + j", 10},
+		{"4j*j", 8i},
+	}
+	for _, test := range tests {
+		got, err := s.Complex128(test.expr)
+		if err != nil {
+			t.Errorf("Complex128(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Complex128(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"4 j", "4jk"} {
+		if _, err := s.Complex128(expr); err == nil {
+			t.Errorf("Complex128(%q) expected an error", expr)
+		}
+	}
+	if got, err := s.WithImplicitMultiplication().Complex128("4j + 2 j"); err != nil || got != 4+4i {
+		t.Errorf("Complex128() with implicit multiplication = %v, %v, want (4+4i)", got, err)
+	}
+}
+
+func TestRealIfClose(t *testing.T) {
+	tests := []struct {
+		expr string
+		tol  float64
+		want float64
+		ok   bool
+	}{
+		{"2 + 1e-17i", -1, 2, true},
+		{"2 + 1e-10i", -1, 0, false},
+		{"2 + 1e-10i", 1e-9, 2, true},
+		{"2 - 1e-10i", 1e-9, 2, true},
+		{"1.5", 0, 1.5, true},
+		{"1.5 + 1e-300i", 0, 0, false},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.RealIfClose(test.expr, test.tol)
+		if (err == nil) != test.ok {
+			t.Errorf("RealIfClose(%q, %g) error = %v, want ok = %v", test.expr, test.tol, err, test.ok)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("RealIfClose(%q, %g) = %v, want %v", test.expr, test.tol, got, test.want)
+		}
+	}
+	if _, err := (Scope{}).RealIfClose(`"a"`, 1); err == nil {
+		t.Errorf("RealIfClose() of a string expected an error")
+	}
+}
@@ -0,0 +1,65 @@
+package calc
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// CostWeights are the weights of the syntax nodes of an expression, used
+// by [Scope.Cost].
+type CostWeights struct {
+	Node  int // literals, identifiers, parentheses, and any other node.
+	Op    int // unary and binary operators, except shifts.
+	Shift int // the << and >> operators, that can build huge integers.
+	Call  int // function calls, their arguments are weighted separately.
+}
+
+// DefaultCostWeights are the weights used by [Scope.Cost], unless
+// configured with [Scope.WithCostWeights].
+var DefaultCostWeights = CostWeights{Node: 1, Op: 1, Shift: 10, Call: 5}
+
+// WithCostWeights returns a copy of s where [Scope.Cost] uses the weights
+// 'w'.
+func (s Scope) WithCostWeights(w CostWeights) Scope {
+	s.costWeights = &w
+	return s
+}
+
+// Cost parses 'expr' and returns an estimate of the cost of its
+// evaluation, without evaluating it, so that overly complex expressions can
+// be rejected upfront.
+//
+// The cost is the sum of the weights of the syntax nodes of 'expr', see
+// [DefaultCostWeights]: "1 + 2*3" costs 5 by default, "1 << 62" costs 12,
+// and "sqrt(x)" costs 7. Calls to user-defined functions cost the same as
+// builtins, their body is not taken into account.
+func (s Scope) Cost(expr string) (int, error) {
+	x, _, err := s.Parse(expr)
+	if err != nil {
+		return 0, err
+	}
+	w := DefaultCostWeights
+	if s.costWeights != nil {
+		w = *s.costWeights
+	}
+	cost := 0
+	ast.Inspect(x, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case nil:
+		case *ast.BinaryExpr:
+			if n.Op == token.SHL || n.Op == token.SHR {
+				cost += w.Shift
+			} else {
+				cost += w.Op
+			}
+		case *ast.UnaryExpr:
+			cost += w.Op
+		case *ast.CallExpr:
+			cost += w.Call
+		default:
+			cost += w.Node
+		}
+		return true
+	})
+	return cost, nil
+}
@@ -0,0 +1,36 @@
+package calc
+
+import "testing"
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{"1", 1},
+		{"1 + 2*3", 5},
+		{"-x", 2},
+		{"1 << 62", 12},
+		{"sqrt(x)", 7},
+		{"(1 + 2)", 4},
+		{"hypot(3, 4) >> 1", 19},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Cost(test.expr)
+		if err != nil {
+			t.Errorf("Cost(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Cost(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	s := Scope{}.WithCostWeights(CostWeights{Op: 1, Shift: 100})
+	if got, err := s.Cost("1 + 2<<3 + f(1)"); err != nil || got != 102 {
+		t.Errorf("Cost() with custom weights = %v, %v, want 102", got, err)
+	}
+	if _, err := (Scope{}).Cost("1 +"); err == nil {
+		t.Errorf("Cost() with a syntax error expected an error")
+	}
+}
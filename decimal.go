@@ -0,0 +1,41 @@
+package calc
+
+import (
+	"go/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithDecimalSeparator returns a copy of s where 'r' can be used as the
+// decimal separator, so that with ',' "3,14" is 3.14.
+//
+// The separator is only recognized between the digits of a decimal number:
+// it must immediately follow an integer literal, and be immediately
+// followed by a digit. So with ',', "f(1,5)" is a call to f with the single
+// argument 1.5, and "f(1, 5)" a call with two arguments. The '.' remains a
+// valid decimal separator.
+func (s Scope) WithDecimalSeparator(r rune) Scope {
+	s.decimal = r
+	return s
+}
+
+// rewriteDecimal rewrites numbers using 'sep' as decimal separator into Go
+// float literals.
+func rewriteDecimal(expr string, sep rune) string {
+	var b strings.Builder
+	last := 0
+	for _, l := range scan(expr) {
+		if l.tok != token.INT || !isDecimal(l.lit) || !strings.HasPrefix(expr[l.end:], string(sep)) {
+			continue
+		}
+		next := l.end + utf8.RuneLen(sep)
+		if next >= len(expr) || !isDigit(rune(expr[next])) {
+			continue
+		}
+		b.WriteString(expr[last:l.end])
+		b.WriteString(".")
+		last = next
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package calc
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math/big"
+)
+
+// DivMode is a rounding mode of the integer division, see
+// [Scope.WithDivMode].
+type DivMode int
+
+const (
+	DivTrunc DivMode = iota // round toward zero, like Go: -7/2 is -3.
+	DivFloor                // round toward -∞, like Python: -7/2 is -4.
+	DivCeil                 // round toward +∞: 7/2 is 4.
+	DivRound                // round to the nearest, ties to even: 5/2 is 2, 7/2 is 4.
+)
+
+// WithDivMode returns a copy of s where the integer division rounds the
+// quotient according to 'mode': with DivFloor, "-7 / 2" is -4 instead of
+// -3. Divisions involving floats are not changed.
+//
+// The remainder follows the quotient, so that "a == (a/b)*b + a%b" still
+// holds: with DivFloor, the remainder has the sign of the divisor, like
+// Python's, and "-7 % 2" is 1 instead of -1.
+func (s Scope) WithDivMode(mode DivMode) Scope {
+	s.divMode = mode
+	return s
+}
+
+// divide computes 'x', if it is an integer division or remainder, with
+// the rounding 'mode'.
+func (e *evaluation) divide(x ast.Expr, mode DivMode) (ast.Expr, error) {
+	b, ok := x.(*ast.BinaryExpr)
+	if !ok || mode == DivTrunc || (b.Op != token.QUO && b.Op != token.REM) {
+		return x, nil
+	}
+	// Checking x first reports invalid operands, and divisions by zero.
+	tv, err := e.checkConst(x)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tv.Type.Underlying().(*types.Basic); !ok || t.Info()&types.IsInteger == 0 {
+		return x, nil
+	}
+	n, err := e.value(b.X)
+	if err != nil {
+		return nil, err
+	}
+	d, err := e.value(b.Y)
+	if err != nil {
+		return nil, err
+	}
+	a, _ := toRat(n)
+	r, _ := toRat(d)
+	q := quotient(r.Quo(a, r), mode)
+	v := constant.Make(q)
+	if b.Op == token.REM {
+		v = constant.BinaryOp(n, token.SUB, constant.BinaryOp(v, token.MUL, d))
+	}
+	return e.bind(x, types.TypeAndValue{Type: tv.Type, Value: v}), nil
+}
+
+// quotient rounds 'r' to an integer according to 'mode'.
+func quotient(r *big.Rat, mode DivMode) *big.Int {
+	switch mode {
+	case DivFloor:
+		// Euclidean division by a positive denominator is the floor.
+		return new(big.Int).Div(r.Num(), r.Denom())
+	case DivCeil:
+		q := new(big.Int).Div(new(big.Int).Neg(r.Num()), r.Denom())
+		return q.Neg(q)
+	case DivRound:
+		return roundHalfEven(r, 0).Num()
+	}
+	return new(big.Int).Quo(r.Num(), r.Denom())
+}
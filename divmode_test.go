@@ -0,0 +1,43 @@
+package calc
+
+import "testing"
+
+func TestWithDivMode(t *testing.T) {
+	tests := []struct {
+		mode DivMode
+		expr string
+		want string
+	}{
+		{DivTrunc, "-7 / 2", "-3"},
+		{DivTrunc, "-7 % 2", "-1"},
+		{DivFloor, "-7 / 2", "-4"},
+		{DivFloor, "-7 % 2", "1"},
+		{DivFloor, "7 % -2", "-1"},
+		{DivFloor, "7 / 2", "3"},
+		{DivCeil, "7 / 2", "4"},
+		{DivCeil, "-7 / 2", "-3"},
+		{DivCeil, "7 % 2", "-1"},
+		{DivRound, "5 / 2", "2"},
+		{DivRound, "7 / 2", "4"},
+		{DivRound, "-7 / 2", "-4"},
+		{DivRound, "8 / 3", "3"},
+		{DivRound, "8 % 3", "-1"},
+		{DivFloor, "-7 / 2.", "-3.5"},
+		{DivFloor, "(-7 / 2) * 2 + -7 % 2", "-7"},
+		{DivFloor, "int8(-7) / 2", "-4"},
+		{DivFloor, "-(1 << 100) / 3", "-422550200076076467165567735126"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.WithDivMode(test.mode).Eval(test.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) in mode %d unexpected error: %v", test.expr, test.mode, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("Eval(%q) in mode %d = %v, want %v", test.expr, test.mode, got, test.want)
+		}
+	}
+	if _, err := (Scope{}).WithDivMode(DivFloor).Eval("1 / 0"); err == nil {
+		t.Errorf("Eval(\"1 / 0\") expected an error")
+	}
+}
@@ -0,0 +1,135 @@
+package calc
+
+import (
+	"fmt"
+	"go/token"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Duration computes the duration expression, see [Scope.Duration].
+func Duration(expr string) (time.Duration, error) { return Scope{}.Duration(expr) }
+
+// Duration evaluates 'expr' as a time.Duration, where numbers can have
+// unit suffixes, like in [time.ParseDuration]: "2h30m", "1.5h" or
+// "2h + 30m*2".
+//
+// Valid units are "ns", "us" (or "µs"), "ms", "s", "m", "h", and "d" for 24
+// hours. Numbers without unit are nanoseconds. Suffixed numbers are
+// computed exactly, the result must be a whole number of nanoseconds.
+func (s Scope) Duration(expr string) (time.Duration, error) {
+	s.durations = true
+	i, err := s.Int(expr)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(i), nil
+}
+
+// durationUnits are the duration units, longest first.
+var durationUnits = []struct {
+	symbol string
+	ns     int64
+}{
+	{"ns", int64(time.Nanosecond)},
+	{"us", int64(time.Microsecond)},
+	{"µs", int64(time.Microsecond)}, // U+00B5 micro sign.
+	{"μs", int64(time.Microsecond)}, // U+03BC Greek letter mu.
+	{"ms", int64(time.Millisecond)},
+	{"s", int64(time.Second)},
+	{"m", int64(time.Minute)},
+	{"h", int64(time.Hour)},
+	{"d", 24 * int64(time.Hour)},
+}
+
+// rewriteDurations rewrites unit-suffixed numbers into nanoseconds:
+// "2h30m" becomes "(2*3600000000000 + 30*60000000000)".
+func rewriteDurations(expr string) string {
+	lexemes := scan(expr)
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(lexemes); i++ {
+		num := lexemes[i]
+		if num.tok != token.INT && num.tok != token.FLOAT || !isDecimal(num.lit) {
+			continue
+		}
+		// A duration is a run of adjacent numbers and identifiers.
+		j := i
+		for j+1 < len(lexemes) && lexemes[j+1].off == lexemes[j].end && isDurationPart(lexemes[j+1].tok) {
+			j++
+		}
+		if j == i {
+			continue
+		}
+		end := lexemes[j].end
+		ns, ok := parseDuration(expr[num.off:end])
+		if !ok {
+			continue
+		}
+		b.WriteString(expr[last:num.off])
+		b.WriteString(ns)
+		last = end
+		i = j
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
+
+func isDurationPart(tok token.Token) bool {
+	return tok == token.INT || tok == token.FLOAT || tok == token.IDENT
+}
+
+// parseDuration converts a sequence of decimal numbers with a unit, like
+// "2h30m", into an expression in nanoseconds.
+func parseDuration(d string) (string, bool) {
+	var terms []string
+	for d != "" {
+		n := strings.IndexFunc(d, func(r rune) bool { return !isDigit(r) && r != '.' })
+		if n <= 0 || !isDecimal(d[:n]) {
+			return "", false
+		}
+		num := d[:n]
+		d = d[n:]
+		found := false
+		for _, u := range durationUnits {
+			if rest, ok := strings.CutPrefix(d, u.symbol); ok {
+				terms = append(terms, fmt.Sprintf("%s*%d", num, u.ns))
+				d, found = rest, true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return "(" + strings.Join(terms, " + ") + ")", true
+}
+
+// unixToInternal is the number of seconds between year 1 and 1970, the
+// offset of Unix times in time.Time.
+const unixToInternal = (1969*365 + 1969/4 - 1969/100 + 1969/400) * 24 * 60 * 60
+
+// Time evaluates the integer expression 'expr' as a number of 'unit' since
+// the Unix epoch (January 1, 1970 UTC), and returns the corresponding UTC
+// time: with 'd' a day in seconds, Time("365*d", time.Second) is January 1,
+// 1971.
+//
+// An error is returned if 'unit' is not positive, or if the time is beyond
+// the range of time.Time.
+func (s Scope) Time(expr string, unit time.Duration) (time.Time, error) {
+	if unit <= 0 {
+		return time.Time{}, fmt.Errorf("invalid unit %v", unit)
+	}
+	n, err := s.BigInt(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ns := n.Mul(n, big.NewInt(int64(unit)))
+	sec, nsec := new(big.Int).DivMod(ns, big.NewInt(int64(time.Second)), new(big.Int))
+	if !sec.IsInt64() || sec.Int64() > math.MaxInt64-unixToInternal || sec.Int64() < math.MinInt64+unixToInternal {
+		return time.Time{}, fmt.Errorf("out of the range of time.Time: %q", expr)
+	}
+	return time.Unix(sec.Int64(), nsec.Int64()).UTC(), nil
+}
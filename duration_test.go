@@ -0,0 +1,75 @@
+package calc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		expr string
+		want time.Duration
+	}{
+		{"2h30m", 2*time.Hour + 30*time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"2h + 30m*2", 3 * time.Hour},
+		{"1d - 1s", 24*time.Hour - time.Second},
+		{"2h30.5s", 2*time.Hour + 30500*time.Millisecond},
+		{"1ms + 1us + 1µs + 1ns", time.Millisecond + 2*time.Microsecond + time.Nanosecond},
+		{".5s", 500 * time.Millisecond},
+		{"1000", 1000},
+		{"(1h + 1m) / 2", 30*time.Minute + 30*time.Second},
+	}
+	for _, test := range tests {
+		got, err := Duration(test.expr)
+		if err != nil {
+			t.Errorf("Duration(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Duration(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{"2x", "0.1ns", "1h30", `"1h"`, "1e400h"} {
+		if _, err := Duration(expr); err == nil {
+			t.Errorf("Duration(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	var c Scope
+	c.Assign("h", "3600")
+	c.Assign("d", "24*h")
+	tests := []struct {
+		expr string
+		unit time.Duration
+		want time.Time
+	}{
+		{"0", time.Second, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"365*d", time.Second, time.Date(1971, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"-d", time.Second, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{"1500", time.Millisecond, time.Date(1970, 1, 1, 0, 0, 1, 5e8, time.UTC)},
+		{"-1", time.Nanosecond, time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC)},
+		{"1<<40", time.Hour, time.Unix(1<<40*3600, 0)},
+	}
+	for _, test := range tests {
+		got, err := c.Time(test.expr, test.unit)
+		if err != nil {
+			t.Errorf("Time(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("Time(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"1<<63", "1.5", "1 << 100"} {
+		if _, err := c.Time(expr, time.Second); err == nil {
+			t.Errorf("Time(%q) expected an error", expr)
+		}
+	}
+	if _, err := c.Time("1", 0); err == nil {
+		t.Errorf("Time() with a zero unit expected an error")
+	}
+}
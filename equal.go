@@ -0,0 +1,104 @@
+package calc
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+)
+
+// EqualScope reports whether s and other define the same variables, with
+// the same types and exact values, and the same imports.
+//
+// The order in which variables were assigned does not matter, and values
+// are compared with [constant.Compare]: "1" and "1.0" are equal if they
+// have the same type. Imports are equal if they have the same name, and
+// their targets are equal scopes.
+//
+// Options set by the With* methods, and user-defined functions, are not
+// compared.
+func (s Scope) EqualScope(other Scope) bool {
+	return equalPackages(s.p, other.p, make(map[[2]*types.Package]bool))
+}
+
+// equalPackages reports whether the packages 'a' and 'b' define the same
+// constants and imports. nil packages are empty.
+//
+// 'seen' holds the pairs being compared, so that cyclic imports terminate.
+func equalPackages(a, b *types.Package, seen map[[2]*types.Package]bool) bool {
+	if a == b {
+		return true
+	}
+	pair := [2]*types.Package{a, b}
+	if seen[pair] {
+		return true
+	}
+	seen[pair] = true
+
+	an, bn := scopeNames(a), scopeNames(b)
+	if len(an) != len(bn) {
+		return false
+	}
+	for i, name := range an {
+		if bn[i] != name {
+			return false
+		}
+		switch x := a.Scope().Lookup(name).(type) {
+		case *types.Const:
+			y, ok := b.Scope().Lookup(name).(*types.Const)
+			if !ok || !types.Identical(x.Type(), y.Type()) || !equalValues(x.Val(), y.Val()) {
+				return false
+			}
+		case *types.PkgName:
+			y, ok := b.Scope().Lookup(name).(*types.PkgName)
+			if !ok || !equalPackages(x.Imported(), y.Imported(), seen) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// scopeNames returns the sorted names defined in 'p', if any.
+func scopeNames(p *types.Package) []string {
+	if p == nil {
+		return nil
+	}
+	return p.Scope().Names()
+}
+
+// equalValues reports whether 'x' and 'y' are the same constant value.
+func equalValues(x, y constant.Value) bool {
+	numeric := func(v constant.Value) bool {
+		switch v.Kind() {
+		case constant.Int, constant.Float, constant.Complex:
+			return true
+		}
+		return false
+	}
+	if x.Kind() != y.Kind() && !(numeric(x) && numeric(y)) {
+		return false
+	}
+	if x.Kind() == constant.Unknown {
+		return true
+	}
+	return constant.Compare(x, token.EQL, y)
+}
+
+// DiffEval evaluates 'expr' in the scopes 'a' and 'b', and reports whether
+// the results are the same exact value, for instance to check how a change
+// in a library of constants affects a formula.
+//
+// Values are compared like in [Scope.EqualScope], except that types are
+// not: "1" and "1.0" are equal. If the evaluation fails in 'a' or 'b', the
+// first error is returned, along with the values computed so far.
+func DiffEval(expr string, a, b Scope) (va, vb constant.Value, equal bool, err error) {
+	if va, err = a.eval(expr); err != nil {
+		return nil, nil, false, err
+	}
+	if vb, err = b.eval(expr); err != nil {
+		return va, nil, false, err
+	}
+	return va, vb, equalValues(va, vb), nil
+}
@@ -0,0 +1,83 @@
+package calc
+
+import "testing"
+
+func TestEqualScope(t *testing.T) {
+	var a, b Scope
+	if !a.EqualScope(b) {
+		t.Errorf("zero scopes should be equal")
+	}
+
+	a.Assign("x", "1")
+	a.Assign("s", `"hello"`)
+	b.Assign("s", `"hel" + "lo"`)
+	if a.EqualScope(b) {
+		t.Errorf("scopes with different names should not be equal")
+	}
+	b.Assign("x", "3/3")
+	if !a.EqualScope(b) || !b.EqualScope(a) {
+		t.Errorf("scopes with the same variables, assigned in a different order, should be equal")
+	}
+
+	c := a.clone()
+	c.Set("x", "1.5")
+	if a.EqualScope(c) {
+		t.Errorf("scopes with different values should not be equal")
+	}
+	c.Set("x", `"1"`)
+	if a.EqualScope(c) {
+		t.Errorf("scopes with values of different kinds should not be equal")
+	}
+	c.Delete("x")
+	c.AssignTyped("x", int64(1))
+	if a.EqualScope(c) {
+		t.Errorf("scopes with values of different types should not be equal")
+	}
+
+	var lib1, lib2 Scope
+	lib1.Assign("K", "1000")
+	lib2.Assign("K", "10*100")
+	a.Import("lib", &lib1)
+	b.Import("lib", &lib2)
+	if !a.EqualScope(b) {
+		t.Errorf("scopes with equivalent imports should be equal")
+	}
+	lib3 := lib2.clone()
+	lib3.Set("K", "1024")
+	b.Delete("lib")
+	b.Import("lib", &lib3)
+	if a.EqualScope(b) {
+		t.Errorf("scopes with different imports should not be equal")
+	}
+	b.Delete("lib")
+	b.Import("other", &lib1)
+	if a.EqualScope(b) {
+		t.Errorf("scopes with differently named imports should not be equal")
+	}
+}
+
+func TestDiffEval(t *testing.T) {
+	var a, b Scope
+	a.Assign("g", "9.81")
+	b.Assign("g", "9.80665")
+	a.Assign("m", "2")
+	b.Assign("m", "2.0")
+
+	va, vb, equal, err := DiffEval("m * g", a, b)
+	if err != nil {
+		t.Fatalf("DiffEval() unexpected error: %v", err)
+	}
+	if equal || va.String() != "19.62" || vb.String() != "19.6133" {
+		t.Errorf("DiffEval() = %v, %v, %v, want 19.62, 19.6133, false", va, vb, equal)
+	}
+	if _, _, equal, err := DiffEval("m + 1", a, b); err != nil || !equal {
+		t.Errorf("DiffEval() = %v, %v, want equal", equal, err)
+	}
+	b.Assign("h", "1")
+	if va, _, _, err := DiffEval("g + h", b, a); err == nil || va == nil {
+		t.Errorf("DiffEval() = %v, %v, want the value in b, and an error", va, err)
+	}
+	if _, _, _, err := DiffEval("g + h", a, b); err == nil {
+		t.Errorf("DiffEval() expected an error")
+	}
+}
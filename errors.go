@@ -0,0 +1,74 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// ErrDivByZero is returned (wrapped) when an expression divides by zero,
+// either with / or %.
+var ErrDivByZero = errors.New("division by zero")
+
+// ErrRecursionLimit is returned (wrapped) when an expression nests, or
+// makes, too many calls to user-defined functions, see [Scope.WithMaxDepth].
+var ErrRecursionLimit = errors.New("recursion limit exceeded")
+
+// ErrIntOverflow is returned (wrapped) by [Scope.Int] when the value is an
+// integer beyond the range of int64.
+var ErrIntOverflow = errors.New("int64 overflow")
+
+// ErrBudgetExceeded is returned (wrapped) when the evaluation time of a
+// Scope is exhausted, see [Scope.WithBudget].
+var ErrBudgetExceeded = errors.New("evaluation budget exceeded")
+
+// ParseError is returned when an expression is syntactically invalid.
+type ParseError struct {
+	Pos token.Position // position of the error, in the expression.
+	Msg string
+}
+
+func (e *ParseError) Error() string { return fmt.Sprintf("%v: %s", e.Pos, e.Msg) }
+
+// parseError converts errors returned by the parser into a *ParseError.
+func parseError(err error) error {
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		return &ParseError{Pos: list[0].Pos, Msg: list[0].Msg}
+	}
+	return err
+}
+
+// sentinelError is an error that also matches one of the sentinel errors
+// with [errors.Is], without changing its message.
+type sentinelError struct {
+	error
+	sentinel error
+}
+
+func (e sentinelError) Is(target error) bool { return target == e.sentinel }
+func (e sentinelError) Unwrap() error        { return e.error }
+
+// classify wraps errors returned by the type checker into sentinel errors
+// when possible.
+func classify(err error) error {
+	var terr types.Error
+	if errors.As(err, &terr) && strings.HasSuffix(terr.Msg, "division by zero") {
+		return sentinelError{err, ErrDivByZero}
+	}
+	return err
+}
+
+// recoverPanic converts a panic during the evaluation of 'expr', if any,
+// into an error stored in 'err'. It must be deferred.
+//
+// Panics are bugs, but a crafted expression must not crash a program
+// embedding calc.
+func recoverPanic(err *error, expr string) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("internal error: %v: %q", r, expr)
+	}
+}
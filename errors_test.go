@@ -0,0 +1,89 @@
+package calc
+
+import (
+	"errors"
+	"go/constant"
+	"strings"
+	"testing"
+)
+
+func TestErrDivByZero(t *testing.T) {
+	var c Scope
+	c.Assign("zero", "0")
+	for _, expr := range []string{"1/0", "1.5/0", "1/0.", "7%0", "1/zero", "1/(2-2)", "(1+2i)/0"} {
+		_, err := c.Eval(expr)
+		if !errors.Is(err, ErrDivByZero) {
+			t.Errorf("Eval(%q) = %v, want ErrDivByZero", expr, err)
+		}
+	}
+	if err := c.Assign("x", "1/zero"); !errors.Is(err, ErrDivByZero) {
+		t.Errorf("Assign() = %v, want ErrDivByZero", err)
+	}
+	if _, err := c.Eval("1/y"); errors.Is(err, ErrDivByZero) {
+		t.Errorf("Eval(%q) = %v, want any other error", "1/y", err)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		expr         string
+		line, column int
+	}{
+		{"1 + / 2", 1, 5},
+		{"(1 + 2", 1, 7},
+		{"1 +\n  2 )", 2, 5},
+	}
+	for _, test := range tests {
+		_, err := Scope{}.Eval(test.expr)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("Eval(%q) = %v, want a *ParseError", test.expr, err)
+			continue
+		}
+		if perr.Pos.Line != test.line || perr.Pos.Column != test.column {
+			t.Errorf("Eval(%q) error at %d:%d, want %d:%d", test.expr, perr.Pos.Line, perr.Pos.Column, test.line, test.column)
+		}
+	}
+
+	// Evaluation errors are not parse errors.
+	var perr *ParseError
+	if _, err := (Scope{}).Int(`"a"`); errors.As(err, &perr) {
+		t.Errorf("Int() = %v, want an evaluation error", err)
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	// Non constant expressions used to panic in the typed accessors.
+	for _, expr := range []string{"println()", "new(int)", "int", "make([]int, 2)", "len([]int{1})", "func(){}", "panic(1)"} {
+		if _, err := (Scope{}).Eval(expr); err == nil {
+			t.Errorf("Eval(%q) expected an error", expr)
+		}
+		if _, err := (Scope{}).Int(expr); err == nil {
+			t.Errorf("Int(%q) expected an error", expr)
+		}
+		if _, err := (Scope{}).Float64(expr); err == nil {
+			t.Errorf("Float64(%q) expected an error", expr)
+		}
+		if _, err := (Scope{}).String(expr); err == nil {
+			t.Errorf("String(%q) expected an error", expr)
+		}
+		var c Scope
+		if err := c.Assign("x", expr); err == nil {
+			t.Errorf("Assign(%q) expected an error", expr)
+		}
+	}
+
+	builtins["crash"] = builtin{nargs: 0, fn: func([]constant.Value) (constant.Value, error) { panic("boom") }}
+	defer delete(builtins, "crash")
+	_, err := Scope{}.Eval("1 + crash()")
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Eval() error = %v, want a recovered panic", err)
+	}
+	c, err := Scope{}.Compile("crash()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Eval(); err == nil {
+		t.Errorf("Compiled.Eval() expected a recovered panic")
+	}
+}
@@ -0,0 +1,155 @@
+package calc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+)
+
+// evaluation holds the state of a single expression evaluation.
+type evaluation struct {
+	fset *token.FileSet
+	pkg  *types.Package // where the expression is checked, can be nil.
+
+	private bool // pkg belongs to this evaluation, and can be changed.
+
+	prec uint // if not 0, the precision of builtins computing in big.Float.
+	std  bool // functions of the standard library namespaces are available.
+
+	infPolicy InfPolicy // handling of infinite results of builtins.
+
+	depth      int               // current number of nested function expansions.
+	maxDepth   int               // maximum number of nested function expansions.
+	expanded   map[ast.Expr]bool // function expansions being rewritten.
+	expansions int               // total number of function expansions.
+
+	resolved map[string]bool // names already passed to the resolver.
+
+	stepping bool   // intermediate steps are recorded.
+	steps    []Step // recorded steps, if stepping.
+
+	tracing bool                 // steps and float literals are logged.
+	floats  map[ast.Expr]float64 // values computed in float64, if tracing.
+	trace   []string             // logged lines, if tracing.
+
+	tracking bool     // variables read are recorded.
+	reads    []string // variables read, if tracking.
+}
+
+// newEvaluation starts a new evaluation in s.
+func (s Scope) newEvaluation() *evaluation {
+	maxDepth := s.maxDepth
+	if maxDepth == 0 {
+		maxDepth = defaultMaxDepth
+	}
+	return &evaluation{
+		fset:      token.NewFileSet(),
+		pkg:       s.env(),
+		std:       s.std,
+		infPolicy: s.infPolicy,
+		maxDepth:  maxDepth,
+		expanded:  make(map[ast.Expr]bool),
+	}
+}
+
+// parse parses 'src', the rewritten form of 'expr'.
+//
+// When rewrites have preserved offsets, positions are reported relative to
+// 'expr'.
+func (e *evaluation) parse(expr, src string) (ast.Expr, error) {
+	x, err := parser.ParseExprFrom(e.fset, "eval", src, 0)
+	var file *token.File
+	e.fset.Iterate(func(f *token.File) bool { file = f; return false })
+	if len(expr) == len(src) {
+		file.SetLinesForContent([]byte(expr))
+	}
+	if err != nil {
+		err = parseError(err)
+		if perr, ok := err.(*ParseError); ok {
+			perr.Pos = file.Position(file.Pos(perr.Pos.Offset))
+		}
+		return nil, err
+	}
+	return x, nil
+}
+
+// addFile adds the file of 'src', the rewritten form of 'expr', as parse
+// does, so that the positions of a tree parsed by another evaluation are
+// valid in e.
+func (e *evaluation) addFile(expr, src string) {
+	file := e.fset.AddFile("eval", -1, len(src))
+	if len(expr) == len(src) {
+		file.SetLinesForContent([]byte(expr))
+	} else {
+		file.SetLinesForContent([]byte(src))
+	}
+}
+
+// check type checks x and returns its type and value.
+func (e *evaluation) check(x ast.Expr) (types.TypeAndValue, error) {
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	if err := types.CheckExpr(e.fset, e.pkg, token.NoPos, x, info); err != nil {
+		return types.TypeAndValue{}, classify(e.suggest(err))
+	}
+	return info.Types[x], nil
+}
+
+// checkConst is like check, but x must be a constant: "println()" or
+// "int" are errors.
+func (e *evaluation) checkConst(x ast.Expr) (types.TypeAndValue, error) {
+	tv, err := e.check(x)
+	if err != nil {
+		return types.TypeAndValue{}, err
+	}
+	if tv.Value == nil {
+		return types.TypeAndValue{}, e.errorf(x.Pos(), "%s is not constant", types.ExprString(x))
+	}
+	return tv, nil
+}
+
+// value type checks x and returns its constant value.
+func (e *evaluation) value(x ast.Expr) (constant.Value, error) {
+	tv, err := e.checkConst(x)
+	return tv.Value, err
+}
+
+// bind defines a temporary variable holding 'tv', and returns an identifier
+// referencing it, in place of 'x'.
+//
+// The variable is named after the source of 'x', so that error messages
+// involving it are still meaningful.
+func (e *evaluation) bind(x ast.Expr, tv types.TypeAndValue) *ast.Ident {
+	if !e.private {
+		e.pkg = Scope{p: e.pkg}.clone().p
+		e.private = true
+	}
+	name := types.ExprString(x)
+	e.pkg.Scope().Insert(types.NewConst(token.NoPos, e.pkg, name, tv.Type, tv.Value))
+	return &ast.Ident{NamePos: x.Pos(), Name: name}
+}
+
+// errorf returns an error at position 'pos', formatted like the type
+// checker's.
+func (e *evaluation) errorf(pos token.Pos, format string, args ...any) error {
+	return types.Error{Fset: e.fset, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// untyped returns the default untyped type of values of the kind of 'v'.
+func untyped(v constant.Value) types.Type {
+	switch v.Kind() {
+	case constant.Bool:
+		return types.Typ[types.UntypedBool]
+	case constant.String:
+		return types.Typ[types.UntypedString]
+	case constant.Int:
+		return types.Typ[types.UntypedInt]
+	case constant.Float:
+		return types.Typ[types.UntypedFloat]
+	case constant.Complex:
+		return types.Typ[types.UntypedComplex]
+	}
+	return types.Typ[types.Invalid]
+}
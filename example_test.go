@@ -2,7 +2,9 @@ package calc_test
 
 import (
 	"fmt"
+	"go/ast"
 	"strconv"
+	"time"
 
 	"github.com/etnz/calc"
 )
@@ -121,3 +123,353 @@ func ExampleScope_Import() {
 	// Output:
 	// Time: 2*time.D + 4*time.H = 187200
 }
+
+// Many namespaces can be organized under a common root.
+func ExampleScope_ImportUnder() {
+	var c, si, us calc.Scope
+	si.Assign("Km", "1000")
+	us.Assign("Mile", "1609.344")
+
+	c.ImportUnder("units", "si", &si)
+	c.ImportUnder("units", "us", &us)
+
+	exp := "26.2*units.us.Mile / units.si.Km"
+	v, _ := c.Float64(exp)
+	fmt.Printf("Marathon: %s = %.1f\n", exp, v)
+
+	// Output:
+	// Marathon: 26.2*units.us.Mile / units.si.Km = 42.2
+}
+
+// Go expressions using the math package constants can be evaluated
+// as is, once the math namespace is enabled.
+func ExampleScope_WithMathNamespace() {
+	c := calc.Scope{}.WithMathNamespace()
+
+	exp := "2 * math.Pi"
+	f, _ := c.Float64(exp)
+	fmt.Println("Float:", exp, "=", f)
+
+	exp = "math.MaxInt8 + 1"
+	v, _ := c.Int(exp)
+	fmt.Println("Int:", exp, "=", v)
+
+	// Output:
+	// Float: 2 * math.Pi = 6.283185307179586
+	// Int: math.MaxInt8 + 1 = 128
+}
+
+// Configuration fields of various types can be filled with a single method.
+func ExampleScope_EvalInto() {
+	var (
+		c       calc.Scope
+		timeout time.Duration
+		retries uint8
+		ratio   float64
+	)
+	c.Assign("s", "1e9") // 1 second in nanoseconds.
+
+	c.EvalInto("2.5*s", &timeout)
+	c.EvalInto("2*3", &retries)
+	c.EvalInto("1/3.", &ratio)
+	fmt.Println(timeout, retries, ratio)
+
+	err := c.EvalInto("256", &retries)
+	fmt.Println(err)
+
+	// Output:
+	// 2.5s 6 0.3333333333333333
+	// not representable as uint8: "256"
+}
+
+// Electronic components values are usually written with SI prefixes.
+func ExampleScope_WithSIPrefixes() {
+	c := calc.Scope{}.WithSIPrefixes()
+
+	for _, exp := range []string{"4.7k", "4k7", "2M + 500k", "100n", "1/(2*3.14159*4k7*100n)"} {
+		f, _ := c.Float64(exp)
+		fmt.Println(exp, "=", f)
+	}
+
+	// Output:
+	// 4.7k = 4700
+	// 4k7 = 4700
+	// 2M + 500k = 2.5e+06
+	// 100n = 1e-07
+	// 1/(2*3.14159*4k7*100n) = 338.6278245200814
+}
+
+// Results can be displayed with SI prefixes too.
+func ExampleScope_Engineering() {
+	c := calc.Scope{}.WithSIPrefixes()
+
+	for _, exp := range []string{"4k7", "1000*1500", "1/(2*3.14159*4k7*100n)", "-0.000022", "1e15"} {
+		str, _ := c.Engineering(exp)
+		fmt.Println(exp, "=", str)
+	}
+
+	// Output:
+	// 4k7 = 4.7k
+	// 1000*1500 = 1.5M
+	// 1/(2*3.14159*4k7*100n) = 338.6278245200814
+	// -0.000022 = -22u
+	// 1e15 = 1e+15
+}
+
+// A user interface can tell whether the displayed value is exact or
+// rounded.
+func ExampleScope_Float64WithExact() {
+	for _, exp := range []string{"1/4.", "1/3."} {
+		f, exact, _ := calc.Scope{}.Float64WithExact(exp)
+		if !exact {
+			fmt.Println(exp, "≈", f)
+			continue
+		}
+		fmt.Println(exp, "=", f)
+	}
+
+	// Output:
+	// 1/4. = 0.25
+	// 1/3. ≈ 0.3333333333333333
+}
+
+// Numbers can be written with a decimal comma.
+func ExampleScope_WithDecimalSeparator() {
+	c := calc.Scope{}.WithDecimalSeparator(',')
+
+	exp := "3,14 * 2"
+	f, _ := c.Float64(exp)
+	fmt.Println(exp, "=", f)
+
+	// Output:
+	// 3,14 * 2 = 6.28
+}
+
+// Expressions can refer to the fields of a struct.
+func ExampleScope_EvalStruct() {
+	type Point struct {
+		X, Y  int
+		Label string
+		next  *Point // unexported fields are ignored.
+	}
+
+	v, _ := calc.Scope{}.EvalStruct("X*X + Y*Y", Point{X: 3, Y: 4})
+	fmt.Println(v)
+
+	v, _ = calc.Scope{}.EvalStruct(`Label + "!"`, &Point{Label: "origin"})
+	fmt.Println(v)
+
+	// Output:
+	// 25
+	// "origin!"
+}
+
+// Functions can be computed with arbitrary precision.
+func ExampleScope_BigFloat() {
+	f, _ := calc.Scope{}.BigFloat("sqrt(2)", 200)
+	fmt.Println(f.Text('g', 60))
+
+	f, _ = calc.Scope{}.BigFloat("sqrt(2)", 53)
+	fmt.Println(f.Text('g', 60))
+
+	// Output:
+	// 1.41421356237309504880168872420969807856967187537694807317668
+	// 1.4142135623730951454746218587388284504413604736328125
+}
+
+// Complex results can be returned in polar form.
+func ExampleScope_Polar() {
+	for _, exp := range []string{"3+4i", "2", "-2", "3i"} {
+		mag, phase, _ := calc.Scope{}.Polar(exp)
+		fmt.Printf("%s = %g∠%.4f\n", exp, mag, phase)
+	}
+
+	// Output:
+	// 3+4i = 5∠0.9273
+	// 2 = 2∠0.0000
+	// -2 = 2∠3.1416
+	// 3i = 3∠1.5708
+}
+
+// Complex results can be formatted in a single call.
+func ExampleScope_FormatComplex() {
+	for _, exp := range []string{"2+3i", "2-3i", "2", "-3i", "0i"} {
+		str, _ := calc.Scope{}.FormatComplex(exp, 'g', -1)
+		fmt.Println(exp, "=", str)
+	}
+	str, _ := calc.Scope{}.FormatComplex("2+3i", 'e', 2)
+	fmt.Println(str)
+
+	// Output:
+	// 2+3i = 2+3i
+	// 2-3i = 2-3i
+	// 2 = 2
+	// -3i = -3i
+	// 0i = 0
+	// 2.00e+00+3.00e+00i
+}
+
+func ExampleScope_EvalHistory() {
+	var s calc.Scope
+	s.EvalHistory("6 * 7")
+	v, _ := s.EvalHistory("ans / 2")
+	fmt.Println(v)
+	v, _ = s.EvalHistory("ans + 1")
+	fmt.Println(v)
+
+	// Output:
+	// 21
+	// 22
+}
+
+func ExampleScope_WithModulus() {
+	s := calc.Scope{}.WithModulus(7)
+	for _, expr := range []string{"5 * 5", "3 - 5", "10", "3*5 == 1"} {
+		v, _ := s.Eval(expr)
+		fmt.Println(expr, "=", v)
+	}
+
+	// Output:
+	// 5 * 5 = 4
+	// 3 - 5 = 5
+	// 10 = 3
+	// 3*5 == 1 = true
+}
+
+func ExampleScope_RatFloat() {
+	r, f, _ := calc.Scope{}.RatFloat("1/3.")
+	fmt.Println(r, f)
+
+	// Output:
+	// 1/3 0.3333333333333333
+}
+
+func ExampleScope_CheckAssign() {
+	var s, lib calc.Scope
+	s.Import("time", &lib)
+
+	fmt.Println(s.CheckAssign("x", "1 + 2"))
+	fmt.Println(s.CheckAssign("x", "1 +"))
+	fmt.Println(s.CheckAssign("time", "1"))
+	fmt.Println(s.Names())
+
+	// Output:
+	// <nil>
+	// eval:1:4: expected operand, found 'EOF'
+	// time is an import
+	// []
+}
+
+func ExampleScope_CompareStrings() {
+	var s calc.Scope
+	s.Assign("name", `"beta"`)
+	c, _ := s.CompareStrings(`"al" + "pha"`, "name")
+	fmt.Println(c)
+
+	// Output:
+	// -1
+}
+
+func ExampleScope_IntChecked() {
+	for _, expr := range []string{"1 << 62", "1 << 63", "1<<64 + 3", "-1<<63 - 1"} {
+		i, overflowed, _ := calc.Scope{}.IntChecked(expr)
+		fmt.Println(expr, "=", i, overflowed)
+	}
+
+	// Output:
+	// 1 << 62 = 4611686018427387904 false
+	// 1 << 63 = -9223372036854775808 true
+	// 1<<64 + 3 = 3 true
+	// -1<<63 - 1 = 9223372036854775807 true
+}
+
+func ExampleScope_Script() {
+	var lib calc.Scope
+	lib.Assign("S", "1")
+
+	s := calc.Scope{}.WithRecording()
+	s.Import("time", &lib)
+	s.Assign("h", "3600*time.S")
+	s.Assign("d", "24*h")
+	s.Assign("h", "60") // no effect, not recorded.
+	s.Set("d", "2*d")
+	script := s.Script()
+	fmt.Print(script)
+
+	// Replay the script in a new scope.
+	var c calc.Scope
+	if err := c.Load(script, map[string]*calc.Scope{"time": &lib}); err != nil {
+		fmt.Println(err)
+	}
+	d, _ := c.Int("d")
+	fmt.Println("d =", d)
+
+	// Output:
+	// import time
+	// h = 3600*time.S
+	// d = 24*h
+	// d = 2*d
+	// d = 172800
+}
+
+func ExampleScope_Parse() {
+	x, fset, _ := calc.Scope{}.Parse("2*h + sqrt(d)")
+	ast.Inspect(x, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			fmt.Println(fset.Position(id.Pos()), id.Name)
+		}
+		return true
+	})
+
+	// Output:
+	// eval:1:3 h
+	// eval:1:7 sqrt
+	// eval:1:12 d
+}
+
+func ExampleScope_Index() {
+	s := calc.Scope{}.WithNegativeIndices()
+	for _, expr := range []string{"2*2", "-1", "5", "-6"} {
+		i, err := s.Index(expr, 5)
+		fmt.Println(i, err)
+	}
+
+	// Output:
+	// 4 <nil>
+	// 4 <nil>
+	// 0 index out of range [-5, 5): "5"
+	// 0 index out of range [-5, 5): "-6"
+}
+
+func ExampleScopeBuilder() {
+	var lib calc.Scope
+	lib.Assign("K", "1000")
+
+	s, err := calc.NewBuilder().
+		Assign("s", "1").
+		Assign("m", "60*s").
+		Assign("h", "60*ms"). // typo
+		Import("units", &lib).
+		Build()
+	fmt.Println(err)
+
+	v, _ := s.Int("m * units.K")
+	fmt.Println(v)
+
+	// Output:
+	// h: eval:1:4: undefined: ms (did you mean m?)
+	// 60000
+}
+
+func ExampleScope_SciParts() {
+	for _, expr := range []string{"1234.5", "-0.00042", "0", "1e400 / 3"} {
+		m, e, _ := calc.Scope{}.SciParts(expr)
+		fmt.Printf("%s = %v × 10^%d\n", expr, m, e)
+	}
+
+	// Output:
+	// 1234.5 = 1.2345 × 10^3
+	// -0.00042 = -4.2 × 10^-4
+	// 0 = 0 × 10^0
+	// 1e400 / 3 = 3.3333333333333335 × 10^399
+}
@@ -0,0 +1,108 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// explanations rewrite the messages of the type checker, from the most
+// specific to the most general.
+var explanations = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`^undefined: (\S+) \(did you mean (\S+)\?\)$`), "$1 is not defined, did you mean $2?"},
+	{regexp.MustCompile(`^undefined: (\S+)$`), "$1 is not defined"},
+	{regexp.MustCompile(`^constant (\S+) overflows (\S+)$`), "$1 is too large for $2"},
+	{regexp.MustCompile(`^cannot convert (\S+) \(.*\) to type (\S+)$`), "$1 cannot be converted to $2"},
+	{regexp.MustCompile(`^invalid operation: .* \(mismatched types (?:untyped )?(\S+) and (?:untyped )?(\S+)\)$`), "$1 and $2 values cannot be mixed"},
+	{regexp.MustCompile(`^invalid operation: operator (\S+) not defined on .*\((?:untyped )?(\S+) constant\)$`), "$1 cannot be used on $2 values"},
+	{regexp.MustCompile(`^invalid operation: invalid shift count (\S+) .*$`), "$1 is too large a shift count"},
+}
+
+// Explain evaluates 'expr', and returns a human-friendly explanation of
+// why it fails, or "" if it succeeds. For instance:
+//
+//	x + 1         x is not defined at column 1
+//	(1 + 2        a parenthesis is not closed at column 7
+//	1 +           the expression is incomplete at column 4
+//	int8(300)     300 is too large for int8 at column 6
+//	1 + "a"       int and string values cannot be mixed at column 1
+//	1/0           division by zero at column 3
+//
+// The explanation is meant for end users, it is not stable: programs
+// should use the errors returned by the evaluation methods instead.
+func (s Scope) Explain(expr string) string {
+	_, err := s.eval(expr)
+	return ExplainError(err)
+}
+
+// ExplainError returns a human-friendly explanation of 'err', an error
+// returned by the evaluation methods of a [Scope], or "" if 'err' is nil.
+// It explains errors like [Scope.Explain] does, and also the errors of the
+// conversions, like [Scope.Int]: "the result is too large for int64".
+func ExplainError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var (
+		perr *ParseError
+		terr types.Error
+	)
+	switch {
+	case errors.Is(err, ErrDivByZero):
+		return "division by zero" + at(positionOf(err))
+	case errors.Is(err, ErrRecursionLimit):
+		return "too many function calls" + at(positionOf(err))
+	case errors.Is(err, ErrIntOverflow):
+		return "the result is too large for int64"
+	case errors.Is(err, ErrBudgetExceeded):
+		return "the evaluation time budget is exhausted, try again later"
+	case errors.As(err, &perr):
+		msg := "syntax error: " + perr.Msg
+		switch {
+		case strings.HasPrefix(perr.Msg, "expected ')'"):
+			msg = "a parenthesis is not closed"
+		case strings.HasSuffix(perr.Msg, "found ')'"):
+			msg = "a parenthesis is closed but not opened"
+		case strings.HasSuffix(perr.Msg, "found 'EOF'"):
+			msg = "the expression is incomplete"
+		}
+		return msg + at(perr.Pos)
+	case errors.As(err, &terr):
+		msg := terr.Msg
+		for _, x := range explanations {
+			if x.re.MatchString(msg) {
+				msg = x.re.ReplaceAllString(msg, x.repl)
+				break
+			}
+		}
+		return msg + at(terr.Fset.Position(terr.Pos))
+	}
+	return err.Error()
+}
+
+// positionOf returns the position of the type checker error 'err', if any.
+func positionOf(err error) token.Position {
+	var terr types.Error
+	if errors.As(err, &terr) && terr.Fset != nil {
+		return terr.Fset.Position(terr.Pos)
+	}
+	return token.Position{}
+}
+
+// at returns " at column c", or " at line l, column c" if the expression
+// has several lines, or "" if 'pos' is unknown.
+func at(pos token.Position) string {
+	switch {
+	case !pos.IsValid():
+		return ""
+	case pos.Line > 1:
+		return fmt.Sprintf(" at line %d, column %d", pos.Line, pos.Column)
+	}
+	return fmt.Sprintf(" at column %d", pos.Column)
+}
@@ -0,0 +1,54 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	var s Scope
+	s.Assign("rate", "2")
+	s.DefineFunc("loop", []string{"x"}, "loop(x)")
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"rate * 2", ""},
+		{"x + 1", "x is not defined at column 1"},
+		{"rat + 1", "rat is not defined, did you mean rate? at column 1"},
+		{"(1 + 2", "a parenthesis is not closed at column 7"},
+		{"1 + 2)", "a parenthesis is closed but not opened at column 6"},
+		{"1 +", "the expression is incomplete at column 4"},
+		{"1 +\n*", "the expression is incomplete at line 2, column 2"},
+		{"int8(300)", "300 is too large for int8 at column 6"},
+		{"int(1.5)", "1.5 cannot be converted to int at column 5"},
+		{`1 + "a"`, "int and string values cannot be mixed at column 1"},
+		{"1 % 0.5", "% cannot be used on float values at column 1"},
+		{"1 << 100000", "100000 is too large a shift count at column 6"},
+		{"1/0", "division by zero at column 3"},
+		{"loop(1)", "too many function calls at column 1"},
+		{"sqrt(1, 2)", "sqrt expects 1 arguments, got 2 at column 1"},
+	}
+	for _, test := range tests {
+		if got := s.Explain(test.expr); got != test.want {
+			t.Errorf("Explain(%q) = %q, want %q", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestExplainError(t *testing.T) {
+	_, err := Scope{}.Int("1 << 63")
+	if !errors.Is(err, ErrIntOverflow) {
+		t.Errorf("Int(\"1 << 63\") error = %v, want ErrIntOverflow", err)
+	}
+	if got, want := ExplainError(err), "the result is too large for int64"; got != want {
+		t.Errorf("ExplainError(%v) = %q, want %q", err, got, want)
+	}
+	_, err = Scope{}.Int("x")
+	if got, want := ExplainError(err), "x is not defined at column 1"; got != want {
+		t.Errorf("ExplainError(%v) = %q, want %q", err, got, want)
+	}
+	if got := ExplainError(nil); got != "" {
+		t.Errorf("ExplainError(nil) = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,30 @@
+package calc
+
+// IntOr evaluates 'expr' as an int64 like [Scope.Int], or 'fallbackExpr'
+// if it fails, for instance because a variable is not defined: with 'x'
+// undefined, IntOr("x", "42") is 42.
+//
+// Any error of 'expr' selects the fallback, and only the error of
+// 'fallbackExpr' is returned, if it fails too.
+func (s Scope) IntOr(expr, fallbackExpr string) (int64, error) {
+	if i, err := s.Int(expr); err == nil {
+		return i, nil
+	}
+	return s.Int(fallbackExpr)
+}
+
+// Float64Or is like [Scope.IntOr], for a float64.
+func (s Scope) Float64Or(expr, fallbackExpr string) (float64, error) {
+	if f, err := s.Float64(expr); err == nil {
+		return f, nil
+	}
+	return s.Float64(fallbackExpr)
+}
+
+// StringOr is like [Scope.IntOr], for a string.
+func (s Scope) StringOr(expr, fallbackExpr string) (string, error) {
+	if str, err := s.String(expr); err == nil {
+		return str, nil
+	}
+	return s.String(fallbackExpr)
+}
@@ -0,0 +1,42 @@
+package calc
+
+import "testing"
+
+func TestIntOr(t *testing.T) {
+	var s Scope
+	s.Assign("x", "7")
+	s.Assign("name", `"calc"`)
+	tests := []struct {
+		expr, fallback string
+		want           int64
+	}{
+		{"x * 2", "42", 14},
+		{"missing", "42", 42},
+		{"1 +", "x", 7},
+		{"1.5", "x + 1", 8},
+		{"name", "-1", -1},
+	}
+	for _, test := range tests {
+		got, err := s.IntOr(test.expr, test.fallback)
+		if err != nil {
+			t.Errorf("IntOr(%q, %q) unexpected error: %v", test.expr, test.fallback, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("IntOr(%q, %q) = %v, want %v", test.expr, test.fallback, got, test.want)
+		}
+	}
+	if _, err := s.IntOr("missing", "other"); err == nil || err.Error() != "eval:1:1: undefined: other" {
+		t.Errorf("IntOr() error = %v, want the error of the fallback", err)
+	}
+
+	if f, err := s.Float64Or("missing / 2", "x / 2."); err != nil || f != 3.5 {
+		t.Errorf("Float64Or() = %v, %v, want 3.5", f, err)
+	}
+	if str, err := s.StringOr("x", "name"); err != nil || str != "calc" {
+		t.Errorf("StringOr() = %q, %v, want \"calc\"", str, err)
+	}
+	if str, err := s.StringOr("name + \"!\"", `"none"`); err != nil || str != "calc!" {
+		t.Errorf("StringOr() = %q, %v, want \"calc!\"", str, err)
+	}
+}
@@ -0,0 +1,141 @@
+package calc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+)
+
+// defaultMaxDepth is the default maximum number of nested user-defined
+// function calls.
+const defaultMaxDepth = 1000
+
+// maxExpansions is the maximum number of user-defined function calls
+// expanded in one evaluation.
+const maxExpansions = 10000
+
+// WithMaxDepth returns a copy of s where expressions can nest at most 'n'
+// calls to user-defined functions, instead of 1000. Beyond, the evaluation
+// fails with [ErrRecursionLimit].
+//
+// The limit protects from recursive functions, like "f(x) = f(x)", whose
+// expansion never ends. If n is not positive, the default limit is used.
+//
+// Independently, an evaluation fails with [ErrRecursionLimit] after 10000
+// calls in total, nested or not: "f(x) = g(x) + g(x)", where g calls h
+// twice, and so on, expands exponentially without any recursion.
+func (s Scope) WithMaxDepth(n int) Scope {
+	s.maxDepth = max(n, 0)
+	return s
+}
+
+// function is a user-defined function.
+type function struct {
+	params []string
+	body   string
+}
+
+// DefineFunc defines the function 'name', computing 'body' from the
+// parameters 'params'. So after
+//
+//	s.DefineFunc("sq", []string{"x"}, "x*x")
+//
+// "sq(5)" is 25.
+//
+// Calls are expanded before evaluation, by substituting the arguments to
+// the parameters in 'body', like [Scope.Substitute] does. The body can also
+// refer to the variables of the scope, and call other functions. Recursive
+// calls are reported as errors after 1000 nested expansions, or 10000
+// expansions in total, see [Scope.WithMaxDepth].
+//
+// User-defined functions take precedence over builtin functions with the
+// same name. If the function 'name' already exists, it is replaced.
+func (s *Scope) DefineFunc(name string, params []string, body string) error {
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("invalid function name %q", name)
+	}
+	seen := make(map[string]bool)
+	for _, p := range params {
+		if !token.IsIdentifier(p) {
+			return fmt.Errorf("invalid parameter name %q", p)
+		}
+		if seen[p] {
+			return fmt.Errorf("duplicate parameter %s", p)
+		}
+		seen[p] = true
+	}
+	src, err := s.rewrite(body)
+	if err != nil {
+		return err
+	}
+	if _, err := parser.ParseExpr(src); err != nil {
+		return parseError(err)
+	}
+	if s.funcs == nil {
+		s.funcs = make(map[string]*function)
+	}
+	s.funcs[name] = &function{params: params, body: body}
+	return nil
+}
+
+// expand replaces 'x' by the body of the user-defined function it calls,
+// if any.
+func (s Scope) expand(e *evaluation, x ast.Expr) (ast.Expr, error) {
+	call, ok := x.(*ast.CallExpr)
+	if !ok {
+		return x, nil
+	}
+	id, ok := call.Fun.(*ast.Ident)
+	if !ok {
+		return x, nil
+	}
+	f, ok := s.funcs[id.Name]
+	if !ok {
+		return x, nil
+	}
+	if len(call.Args) != len(f.params) || call.Ellipsis.IsValid() {
+		return nil, e.errorf(call.Pos(), "%s expects %d arguments, got %d", id.Name, len(f.params), len(call.Args))
+	}
+	if e.depth >= e.maxDepth {
+		err := e.errorf(call.Pos(), "%s: too many nested calls (more than %d)", id.Name, e.maxDepth)
+		return nil, sentinelError{err, ErrRecursionLimit}
+	}
+	if e.expansions >= maxExpansions {
+		err := e.errorf(call.Pos(), "%s: too many calls (more than %d)", id.Name, maxExpansions)
+		return nil, sentinelError{err, ErrRecursionLimit}
+	}
+
+	repl := make(map[string]string, len(f.params))
+	for i, p := range f.params {
+		repl[p] = types.ExprString(call.Args[i])
+	}
+	body, err := s.rewrite(f.body)
+	if err != nil {
+		return nil, err
+	}
+	if body, err = s.Substitute(body, repl); err != nil {
+		return nil, err
+	}
+	// Parsing in the evaluation file set reports errors in the body
+	// relative to it: "sq:1:3".
+	bx, err := parser.ParseExprFrom(e.fset, id.Name, body, 0)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	paren := &ast.ParenExpr{Lparen: call.Pos(), X: bx, Rparen: call.Rparen}
+	e.depth++
+	e.expansions++
+	e.expanded[paren] = true
+	return paren, nil
+}
+
+// unexpand must be called on each node after it has been rewritten, to
+// track the end of expansions.
+func (e *evaluation) unexpand(x ast.Expr) {
+	if e.expanded[x] {
+		delete(e.expanded, x)
+		e.depth--
+	}
+}
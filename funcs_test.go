@@ -0,0 +1,96 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDefineFunc(t *testing.T) {
+	var c Scope
+	c.Assign("h", "3600")
+	for _, def := range []struct {
+		name   string
+		params []string
+		body   string
+	}{
+		{"sq", []string{"x"}, "x*x"},
+		{"add", []string{"x", "y"}, "x+y"},
+		{"hours", []string{"n"}, "n*h"},
+		{"norm", []string{"x", "y"}, "sqrt(sq(x) + sq(y))"},
+		{"answer", nil, "42"},
+		{"loop", []string{"x"}, "loop(x)"},
+		{"hypot", []string{"x", "y"}, "x+y"},
+	} {
+		if err := c.DefineFunc(def.name, def.params, def.body); err != nil {
+			t.Fatalf("DefineFunc(%q) unexpected error: %v", def.name, err)
+		}
+	}
+
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"sq(5)", 25},
+		{"sq(2+3)", 25}, // precedence is preserved.
+		{"add(1, 2)*3", 9},
+		{"sq(add(1, 2))", 9},
+		{"hours(2)", 7200},
+		{"norm(3, 4)", 5},
+		{"answer()", 42},
+		{"hypot(3, 4)", 7}, // user-defined functions take precedence.
+		{"sq(sq(sq(2)))", 256},
+	}
+	for _, test := range tests {
+		got, err := c.Float64(test.expr)
+		if err != nil {
+			t.Errorf("Float64(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Float64(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{"sq()", "sq(1, 2)", "loop(1)"} {
+		if _, err := c.Float64(expr); err == nil {
+			t.Errorf("Float64(%q) expected an error", expr)
+		}
+	}
+	if _, err := c.Float64("loop(1)"); !errors.Is(err, ErrRecursionLimit) || !strings.Contains(err.Error(), "more than 1000") {
+		t.Errorf("Float64(%q) = %v, want a recursion error", "loop(1)", err)
+	}
+	if _, err := c.WithMaxDepth(10).Float64("loop(1)"); !errors.Is(err, ErrRecursionLimit) || !strings.Contains(err.Error(), "more than 10)") {
+		t.Errorf("WithMaxDepth(10).Float64(%q) = %v, want a recursion error", "loop(1)", err)
+	}
+	if _, err := c.WithMaxDepth(1).Float64("sq(2) + sq(3)"); err != nil {
+		t.Errorf("WithMaxDepth(1).Float64(%q) unexpected error: %v", "sq(2) + sq(3)", err)
+	}
+	// f40 makes 2**40 calls, without recursion.
+	c.DefineFunc("f0", []string{"x"}, "x")
+	for i := 1; i <= 40; i++ {
+		c.DefineFunc(fmt.Sprintf("f%d", i), []string{"x"}, fmt.Sprintf("f%d(x) + f%d(x)", i-1, i-1))
+	}
+	if got, err := c.Float64("f10(1)"); err != nil || got != 1024 {
+		t.Errorf("Float64(%q) = %v, %v, want 1024", "f10(1)", got, err)
+	}
+	if _, err := c.Float64("f40(1)"); !errors.Is(err, ErrRecursionLimit) || !strings.Contains(err.Error(), "more than 10000") {
+		t.Errorf("Float64(%q) = %v, want a recursion error", "f40(1)", err)
+	}
+
+	for _, def := range []struct {
+		name   string
+		params []string
+		body   string
+	}{
+		{"1f", nil, "1"},
+		{"f", []string{"x", "x"}, "x"},
+		{"f", []string{"x+1"}, "x"},
+		{"f", []string{"x"}, "x+"},
+	} {
+		if err := c.DefineFunc(def.name, def.params, def.body); err == nil {
+			t.Errorf("DefineFunc(%q, %q, %q) expected an error", def.name, def.params, def.body)
+		}
+	}
+}
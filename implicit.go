@@ -0,0 +1,73 @@
+package calc
+
+import (
+	"go/token"
+	"strings"
+)
+
+// WithImplicitMultiplication returns a copy of s where multiplication can
+// be implicit, like in mathematics: "2x" is "2*x", and "3(a+b)" is
+// "3*(a+b)".
+//
+// A '*' is inserted between two tokens, whether they are separated by
+// spaces or not, when the first one is a decimal number or a closing
+// parenthesis, and the second one is an identifier or an opening
+// parenthesis, or a number after a closing parenthesis:
+//
+//	2x       2*x
+//	2 x      2*x
+//	2.5(a+b) 2.5*(a+b)
+//	(a+b)(c) (a+b)*(c)
+//	(a+b)2   (a+b)*2
+//	2x**2    2*x**2 (with the power operator, 2*(x**2))
+//
+// Other combinations are left untouched, in particular:
+//
+//	f(x)  is a function call: an identifier followed by a parenthesis.
+//	xy    is the identifier 'xy', and "x y" an error.
+//	2 3   is an error.
+//	2i    is the imaginary number, like in Go.
+//	1e3   is the float 1000, like in Go.
+//	0x1f  is the hexadecimal 31: hexadecimal, octal and binary literals are
+//	      never multiplied implicitly, "0x1g" is an error.
+//
+// Implicit products have the precedence of '*': "1/2x" is "(1/2)*x". Note
+// also that "(f)(x)" is a product, not a call.
+func (s Scope) WithImplicitMultiplication() Scope {
+	s.implicit = true
+	return s
+}
+
+// rewriteImplicit inserts the implicit multiplication operators in 'expr'.
+func rewriteImplicit(expr string) string {
+	lexemes := scan(expr)
+	var b strings.Builder
+	last := 0
+	for i := 0; i+1 < len(lexemes); i++ {
+		x, y := lexemes[i], lexemes[i+1]
+		if !implicitProduct(x, y) {
+			continue
+		}
+		b.WriteString(expr[last:y.off])
+		b.WriteString("*")
+		last = y.off
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
+
+// implicitProduct reports whether there is an implicit multiplication
+// between 'x' and 'y'.
+func implicitProduct(x, y lexeme) bool {
+	switch {
+	case isNumber(x.tok):
+		return isDecimal(x.lit) && (y.tok == token.IDENT || y.tok == token.LPAREN)
+	case x.tok == token.RPAREN:
+		return y.tok == token.IDENT || y.tok == token.LPAREN || isNumber(y.tok)
+	}
+	return false
+}
+
+func isNumber(tok token.Token) bool {
+	return tok == token.INT || tok == token.FLOAT || tok == token.IMAG
+}
@@ -0,0 +1,82 @@
+package calc
+
+import "testing"
+
+func TestImplicitMultiplication(t *testing.T) {
+	var c Scope
+	c.Assign("x", "3")
+	c.Assign("a", "1")
+	c.Assign("b", "2")
+	c.Assign("xy", "100")
+	c = c.WithImplicitMultiplication()
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		// Number followed by an identifier.
+		{"2x", 6},
+		{"2 x", 6},
+		{"2.5x", 7.5},
+		{".5x", 1.5},
+		{"2.x", 6},
+		{"1e1x", 30},
+		{"2xy", 200},
+		{"-2x + 1", -5},
+		{"x/2x", 3}, // "x/2*x", like in Go: (3/2)*3.
+		// Number followed by a parenthesis.
+		{"3(a+b)", 9},
+		{"3 (a+b)", 9},
+		{"2(3)(4)", 24},
+		// Closing parenthesis followed by something.
+		{"(a+b)(a+b)", 9},
+		{"(a+b)x", 9},
+		{"(a+b)2", 6},
+		{"(a+b) 2", 6},
+		// Untouched.
+		{"sqrt(4)", 2},
+		{"sqrt(4)x", 6},
+		{"2sqrt(4)", 4},
+		{"0x10", 16},
+		{"0x1f", 31},
+		{"0b11", 3},
+		{"1e3", 1000},
+		{"2*x", 6},
+		{"x", 3},
+		{"real(2i)", 0},
+		// Other rewrites.
+		{"2,5x", 7.5},
+	}
+	for _, test := range tests {
+		s := c
+		if test.expr == "2,5x" {
+			s = s.WithDecimalSeparator(',')
+		}
+		got, err := s.Float64(test.expr)
+		if err != nil {
+			t.Errorf("Float64(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Float64(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{"2 3", "x y", "0x1g", "0b1x", "x2"} {
+		if _, err := c.Float64(expr); err == nil {
+			t.Errorf("Float64(%q) expected an error", expr)
+		}
+	}
+
+	// With SI prefixes, suffixes win.
+	if got, err := c.WithSIPrefixes().Float64("2k x"); err != nil || got != 6000 {
+		t.Errorf("Float64(%q) = %v, %v, want 6000", "2k x", got, err)
+	}
+	// With the power operator, powers bind tighter.
+	if got, err := c.WithPowerOperator().Float64("2x**2"); err != nil || got != 18 {
+		t.Errorf("Float64(%q) = %v, %v, want 18", "2x**2", got, err)
+	}
+	// Without the option, implicit multiplications are errors.
+	if _, err := (Scope{}).Float64("2(3)"); err == nil {
+		t.Errorf("Float64(%q) expected an error", "2(3)")
+	}
+}
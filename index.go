@@ -0,0 +1,55 @@
+package calc
+
+import (
+	"fmt"
+	"slices"
+)
+
+// WithNegativeIndices returns a copy of s where [Scope.Index], and the
+// indexing of slice variables (see [Scope.AssignSlice]), accept negative
+// indices, counting from the end: -1 is the last element, -2 the one
+// before, and so on.
+func (s Scope) WithNegativeIndices() Scope {
+	s.negIndex = true
+	return s
+}
+
+// Index evaluates the integer expression 'expr' as an index in a sequence
+// of 'length' elements: the result must be in the range [0, length).
+//
+// See [Scope.WithNegativeIndices] to count from the end.
+func (s Scope) Index(expr string, length int) (int, error) {
+	i, err := s.Int(expr)
+	if err != nil {
+		return 0, err
+	}
+	if s.negIndex && i < 0 {
+		i += int64(length)
+	}
+	if i < 0 || i >= int64(length) {
+		if s.negIndex {
+			return 0, fmt.Errorf("index out of range [%d, %d): %q", -length, length, expr)
+		}
+		return 0, fmt.Errorf("index out of range [0, %d): %q", length, expr)
+	}
+	return int(i), nil
+}
+
+// Bucket evaluates 'expr' as a float64, and returns the index of the
+// histogram bucket it falls into, given the sorted upper bounds of the
+// buckets: the index of the first bound greater or equal to the value. With
+// bounds [10, 100], 5 and 10 are in bucket 0, 50 in bucket 1, and values
+// above the last bound, like 500, in bucket len(bounds), 2.
+//
+// An error is returned if 'bounds' is not sorted in increasing order.
+func (s Scope) Bucket(expr string, bounds []float64) (int, error) {
+	if !slices.IsSorted(bounds) {
+		return 0, fmt.Errorf("bucket bounds are not sorted: %v", bounds)
+	}
+	f, err := s.Float64(expr)
+	if err != nil {
+		return 0, err
+	}
+	i, _ := slices.BinarySearch(bounds, f)
+	return i, nil
+}
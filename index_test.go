@@ -0,0 +1,43 @@
+package calc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBucket(t *testing.T) {
+	bounds := []float64{10, 100, 1000}
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{"5", 0},
+		{"10", 0},
+		{"10.5", 1},
+		{"-1e300", 0},
+		{"999 + 1", 2},
+		{"1000.001", 3},
+		{"1e400", 3},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Bucket(test.expr, bounds)
+		if err != nil {
+			t.Errorf("Bucket(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Bucket(%q) = %d, want %d", test.expr, got, test.want)
+		}
+	}
+	if got, err := (Scope{}).Bucket("1", nil); err != nil || got != 0 {
+		t.Errorf("Bucket() without bounds = %d, %v, want 0", got, err)
+	}
+	for _, bounds := range [][]float64{{100, 10}, {1, math.NaN()}} {
+		if _, err := (Scope{}).Bucket("1", bounds); err == nil {
+			t.Errorf("Bucket() with bounds %v expected an error", bounds)
+		}
+	}
+	if _, err := (Scope{}).Bucket(`"a"`, bounds); err == nil {
+		t.Errorf("Bucket() of a string expected an error")
+	}
+}
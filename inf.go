@@ -0,0 +1,52 @@
+package calc
+
+import (
+	"errors"
+	"go/constant"
+	"go/token"
+	"math"
+)
+
+// InfPolicy is the handling of infinite results of builtin functions, see
+// [Scope.WithInfPolicy].
+type InfPolicy int
+
+const (
+	// InfError reports infinite results as errors, it is the default.
+	InfError InfPolicy = iota
+	// InfOverflow replaces infinite results with a value beyond the range
+	// of float64, that float accessors convert to an infinity.
+	InfOverflow
+)
+
+// WithInfPolicy returns a copy of s where builtin functions with an
+// infinite result follow the policy 'p'.
+//
+// Go constants cannot be infinite, nor NaN. Builtins computed in float64
+// can produce infinities: hypot, dist and pow when they overflow, and the
+// functions of the standard library, see [StdScope], like math.Log(0) or
+// math.Exp(1000). By default (InfError) these are errors.
+//
+// With InfOverflow, +Inf is replaced by 2**1024, just beyond the largest
+// float64, and -Inf by -2**1024: so [Scope.Float64] of "math.Log(0)" is
+// -Inf. But the replacement is an ordinary number: "math.Log(0) * 0" is 0,
+// not NaN, and builtins still reject it as an argument. NaN results, like
+// math.Log(-1), are always errors.
+func (s Scope) WithInfPolicy(p InfPolicy) Scope {
+	s.infPolicy = p
+	return s
+}
+
+// infinity returns the replacement of the infinite result reported by 'err',
+// if the evaluation's policy allows it.
+func (e *evaluation) infinity(err error) (constant.Value, bool) {
+	var f nonFinite
+	if e.infPolicy != InfOverflow || !errors.As(err, &f) || math.IsNaN(float64(f)) {
+		return nil, false
+	}
+	inf := constant.ToFloat(constant.Shift(constant.MakeInt64(1), token.SHL, 1024))
+	if f < 0 {
+		inf = constant.UnaryOp(token.SUB, inf, 0)
+	}
+	return inf, true
+}
@@ -0,0 +1,44 @@
+package calc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWithInfPolicy(t *testing.T) {
+	s := StdScope()
+	for _, expr := range []string{"math.Log(0)", "pow(10, 400.5)", "hypot(1.5e308, 1.5e308)", "math.Log(-1)"} {
+		if _, err := s.Float64(expr); err == nil {
+			t.Errorf("Float64(%q) expected an error", expr)
+		}
+	}
+
+	s = s.WithInfPolicy(InfOverflow)
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"math.Log(0)", math.Inf(-1)},
+		{"-math.Log(0)", math.Inf(1)},
+		{"pow(10, 400.5)", math.Inf(1)},
+		{"math.Exp(1000)", math.Inf(1)},
+		{"hypot(1.5e308, 1.5e308)", math.Inf(1)},
+		{"math.Log(0) * 0", 0},
+		{"math.Log(1)", 0},
+	}
+	for _, test := range tests {
+		got, err := s.Float64(test.expr)
+		if err != nil {
+			t.Errorf("Float64(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Float64(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"math.Log(-1)", "math.Exp(math.Log(0))"} {
+		if _, err := s.Float64(expr); err == nil {
+			t.Errorf("Float64(%q) expected an error", expr)
+		}
+	}
+}
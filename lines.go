@@ -0,0 +1,36 @@
+package calc
+
+import "strings"
+
+// joinLines rewrites a multi-line expression on a single line.
+//
+// Line breaks between tokens, along with comments, are replaced by spaces.
+// Line breaks inside raw string literals are preserved. The rewritten
+// expression has the same length, so that offsets are preserved.
+func joinLines(expr string) string {
+	if !strings.ContainsAny(expr, "\r\n") {
+		return expr
+	}
+	var b strings.Builder
+	last := 0
+	for _, l := range scan(expr) {
+		gap := expr[last:l.off]
+		if strings.ContainsAny(gap, "\r\n") {
+			gap = strings.Repeat(" ", len(gap))
+		}
+		b.WriteString(gap)
+		b.WriteString(expr[l.off:l.end])
+		last = l.end
+	}
+	gap := expr[last:]
+	if strings.ContainsAny(gap, "\r\n") {
+		gap = strings.Repeat(" ", len(gap))
+	}
+	b.WriteString(gap)
+	return b.String()
+}
+
+// line returns 'expr' on a single line, without surrounding spaces.
+func line(expr string) string {
+	return strings.TrimSpace(joinLines(expr))
+}
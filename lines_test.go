@@ -0,0 +1,27 @@
+package calc
+
+import "testing"
+
+func TestMultiLine(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"2 +\n 3", "5"},
+		{"2\n+ 3", "5"},
+		{"2\r\n+ 3\r\n", "5"},
+		{"(2 +\n3) *\n\n 2\n", "10"},
+		{"2 + // two\n 3 // three", "5"},
+		{"`a\nb` + \"c\"", `"a\nbc"`},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Eval(test.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got.ExactString() != test.want {
+			t.Errorf("Eval(%q) = %s, want %s", test.expr, got.ExactString(), test.want)
+		}
+	}
+}
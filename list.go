@@ -0,0 +1,99 @@
+package calc
+
+import (
+	"fmt"
+	"go/token"
+	"math/big"
+	"strings"
+)
+
+// MaxRangeLen is the maximum length of the sequences returned by
+// [Scope.Range].
+const MaxRangeLen = 1 << 24
+
+// Rats evaluates the comma-separated list of expressions 'list' as exact
+// rationals: "1/3., 0.1, 2" is [1/3, 1/10, 2].
+//
+// Commas inside parentheses, brackets, braces or string literals do not
+// separate elements: "hypot(3, 4), 1" has two elements. An error reports
+// the index of the element that failed.
+func (s Scope) Rats(list string) ([]*big.Rat, error) {
+	var rats []*big.Rat
+	for i, expr := range splitList(list) {
+		r, err := s.rat(expr)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		rats = append(rats, s.limit(r))
+	}
+	return rats, nil
+}
+
+// Range evaluates 'startExpr', 'endExpr' and 'stepExpr' as int64, and
+// returns the sequence from start to end, included, by step:
+// Range("1", "10", "2") is [1 3 5 7 9].
+//
+// A negative step makes a descending sequence, "10", "1", "-3" is
+// [10 7 4 1]. The sequence is empty if the step goes away from end, and a
+// zero step is an error, and so is a sequence longer than [MaxRangeLen].
+func (s Scope) Range(startExpr, endExpr, stepExpr string) ([]int64, error) {
+	start, err := s.Int(startExpr)
+	if err != nil {
+		return nil, err
+	}
+	end, err := s.Int(endExpr)
+	if err != nil {
+		return nil, err
+	}
+	step, err := s.Int(stepExpr)
+	if err != nil {
+		return nil, err
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("zero step: %q", stepExpr)
+	}
+	if (step > 0 && start > end) || (step < 0 && start < end) {
+		return nil, nil
+	}
+	// The distances are computed in uint64, they may overflow int64.
+	dist, stride := uint64(end-start), uint64(step)
+	if step < 0 {
+		dist, stride = uint64(start-end), -stride
+	}
+	if dist/stride >= MaxRangeLen {
+		return nil, fmt.Errorf("range too long: more than %d elements", MaxRangeLen)
+	}
+	seq := make([]int64, 0, dist/stride+1)
+	for i, v := uint64(0), start; i <= dist/stride; i, v = i+1, v+step {
+		seq = append(seq, v)
+	}
+	return seq, nil
+}
+
+// splitList splits 'list' at its top-level commas. Elements are trimmed.
+//
+// An empty list has no element.
+func splitList(list string) []string {
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+	var (
+		elems []string
+		depth int
+		last  int
+	)
+	for _, l := range scan(list) {
+		switch l.tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth--
+		case token.COMMA:
+			if depth == 0 {
+				elems = append(elems, strings.TrimSpace(list[last:l.off]))
+				last = l.end
+			}
+		}
+	}
+	return append(elems, strings.TrimSpace(list[last:]))
+}
@@ -0,0 +1,166 @@
+package calc
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// GoLiteral evaluates 'expr' and renders it as Go source for a constant of
+// the type 'typ', for code generation. The mapping is:
+//
+//	bool                    true or false
+//	string                  a double-quoted string: "a\tb"
+//	int, int8, ..., uint64  a decimal integer: 172800
+//	byte, rune, uintptr     a decimal integer
+//	float32, float64        the nearest float: 0.1, 1e+100
+//	complex64, complex128   the nearest complex: (1+2i)
+//	time.Duration           time.Duration(172800) * time.Second
+//
+// Literals of basic types are untyped, they must be used where a value of
+// type 'typ' is expected: "var x int8 = 100". An error is returned if the
+// value is not representable in 'typ': out of range, or not an integer for
+// integer types.
+//
+// For time.Duration, the value is a number of seconds, rendered in seconds
+// if it is a whole number of them, or else in milli-, micro- or
+// nanoseconds.
+func (s Scope) GoLiteral(expr string, typ string) (string, error) {
+	val, err := s.eval(expr)
+	if err != nil {
+		return "", err
+	}
+	if typ == "time.Duration" {
+		return durationLiteral(val, expr)
+	}
+	t, ok := types.Universe.Lookup(typ).(*types.TypeName)
+	if !ok {
+		return "", fmt.Errorf("unsupported type %s", typ)
+	}
+	b, ok := t.Type().(*types.Basic)
+	if !ok {
+		return "", fmt.Errorf("unsupported type %s", typ)
+	}
+	notRepresentable := fmt.Errorf("not representable as a %s (%v): %q", typ, val.Kind(), expr)
+	switch info := b.Info(); {
+	case info&types.IsBoolean != 0:
+		if val.Kind() != constant.Bool {
+			return "", notRepresentable
+		}
+		return val.String(), nil
+	case info&types.IsString != 0:
+		if val.Kind() != constant.String {
+			return "", notRepresentable
+		}
+		return strconv.Quote(constant.StringVal(val)), nil
+	case info&types.IsInteger != 0:
+		ival := constant.ToInt(val)
+		if ival.Kind() != constant.Int || !inRange(ival, b) {
+			return "", notRepresentable
+		}
+		return ival.ExactString(), nil
+	case info&types.IsFloat != 0:
+		bits := 8 * int(sizes.Sizeof(b))
+		f, err := floatLiteral(val, bits)
+		if err != nil {
+			return "", notRepresentable
+		}
+		return f, nil
+	case info&types.IsComplex != 0:
+		cval := constant.ToComplex(val)
+		if cval.Kind() != constant.Complex {
+			return "", notRepresentable
+		}
+		bits := 4 * int(sizes.Sizeof(b))
+		re, err := floatLiteral(constant.Real(cval), bits)
+		if err != nil {
+			return "", notRepresentable
+		}
+		im, err := floatLiteral(constant.Imag(cval), bits)
+		if err != nil {
+			return "", notRepresentable
+		}
+		if im[0] != '-' {
+			im = "+" + im
+		}
+		return "(" + re + im + "i)", nil
+	}
+	return "", fmt.Errorf("unsupported type %s", typ)
+}
+
+// FloatString evaluates 'expr' as a float64, and formats it in the
+// shortest notation that parses back to the same float64, like
+// [strconv.FormatFloat] with the 'g' format and precision -1: "1/3." is
+// "0.3333333333333333", "2.50" is "2.5", and "1e21" is "1e+21".
+//
+// The notation is stable, for serialization: there is no negative zero,
+// "-0.0" is "0". Complex results, and results that overflow float64, are
+// errors.
+func (s Scope) FloatString(expr string) (string, error) {
+	val, err := s.eval(expr)
+	if err != nil {
+		return "", err
+	}
+	str, err := floatLiteral(val, 64)
+	if err != nil {
+		return "", fmt.Errorf("not representable as a float64, %v (%v): %q", err, val.Kind(), expr)
+	}
+	if str == "-0" {
+		str = "0"
+	}
+	return str, nil
+}
+
+// floatLiteral renders the real 'val' as the nearest float of 'bits' bits.
+func floatLiteral(val constant.Value, bits int) (string, error) {
+	fval := constant.ToFloat(val)
+	if fval.Kind() != constant.Float && fval.Kind() != constant.Int {
+		return "", fmt.Errorf("not a real number")
+	}
+	f, _ := constant.Float64Val(fval)
+	if bits == 32 {
+		f32, _ := constant.Float32Val(fval)
+		f = float64(f32)
+	}
+	if math.IsInf(f, 0) {
+		return "", fmt.Errorf("out of range")
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits), nil
+}
+
+// durationUnitNames are the time.Duration constants tried by
+// durationLiteral, by number of units per second.
+var durationUnitNames = []struct {
+	name   string
+	perSec int64
+}{
+	{"time.Second", 1},
+	{"time.Millisecond", 1e3},
+	{"time.Microsecond", 1e6},
+	{"time.Nanosecond", 1e9},
+}
+
+// durationLiteral renders the number of seconds 'val' as a time.Duration.
+func durationLiteral(val constant.Value, expr string) (string, error) {
+	for _, u := range durationUnitNames {
+		n := constant.BinaryOp(val, token.MUL, constant.MakeInt64(u.perSec))
+		r, ok := toRat(n)
+		if !ok {
+			break
+		}
+		if !r.IsInt() {
+			continue
+		}
+		// The duration in nanoseconds must fit in an int64.
+		ns := new(big.Int).Mul(r.Num(), big.NewInt(1e9/u.perSec))
+		if !ns.IsInt64() {
+			break
+		}
+		return fmt.Sprintf("time.Duration(%v) * %s", r.Num(), u.name), nil
+	}
+	return "", fmt.Errorf("not representable as a time.Duration (%v): %q", val.Kind(), expr)
+}
@@ -0,0 +1,90 @@
+package calc
+
+import "testing"
+
+func TestGoLiteral(t *testing.T) {
+	var c Scope
+	c.Assign("d", "86400")
+	tests := []struct {
+		expr, typ string
+		want      string
+	}{
+		{"1 < 2", "bool", "true"},
+		{`"a" + "\tb"`, "string", `"a\tb"`},
+		{"2*d", "int", "172800"},
+		{"-128", "int8", "-128"},
+		{"255", "byte", "255"},
+		{"'a'", "rune", "97"},
+		{"1 << 64 - 1", "uint64", "18446744073709551615"},
+		{"1/10.", "float64", "0.1"},
+		{"1/10.", "float32", "0.1"},
+		{"1e100", "float64", "1e+100"},
+		{"3", "float64", "3"},
+		{"1 + 2i", "complex128", "(1+2i)"},
+		{"0.5 - 0.25i", "complex64", "(0.5-0.25i)"},
+		{"2*d", "time.Duration", "time.Duration(172800) * time.Second"},
+		{"1.5", "time.Duration", "time.Duration(1500) * time.Millisecond"},
+		{"1e-9", "time.Duration", "time.Duration(1) * time.Nanosecond"},
+	}
+	for _, test := range tests {
+		got, err := c.GoLiteral(test.expr, test.typ)
+		if err != nil {
+			t.Errorf("GoLiteral(%q, %s) unexpected error: %v", test.expr, test.typ, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("GoLiteral(%q, %s) = %s, want %s", test.expr, test.typ, got, test.want)
+		}
+	}
+
+	for _, test := range []struct{ expr, typ string }{
+		{"128", "int8"},
+		{"-1", "uint"},
+		{"1.5", "int"},
+		{"1", "bool"},
+		{"1", "string"},
+		{"1e40", "float32"},
+		{"1e400", "float64"},
+		{"1i", "float64"},
+		{"1e-10", "time.Duration"},
+		{"1e10", "time.Duration"},
+		{"1", "error"},
+		{"1", "big.Int"},
+	} {
+		if _, err := c.GoLiteral(test.expr, test.typ); err == nil {
+			t.Errorf("GoLiteral(%q, %s) expected an error", test.expr, test.typ)
+		}
+	}
+}
+
+func TestFloatString(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"0.1*3", "0.3"},
+		{"0.1", "0.1"},
+		{"1/3.", "0.3333333333333333"},
+		{"2.50", "2.5"},
+		{"42", "42"},
+		{"1e21", "1e+21"},
+		{"-0.0", "0"},
+		{"-1e-300 * 1e-300", "0"},
+		{"1 + 0i", "1"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.FloatString(test.expr)
+		if err != nil {
+			t.Errorf("FloatString(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("FloatString(%q) = %q, want %q", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"1e400", "1i", `"1"`, "1 +"} {
+		if _, err := (Scope{}).FloatString(expr); err == nil {
+			t.Errorf("FloatString(%q) expected an error", expr)
+		}
+	}
+}
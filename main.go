@@ -10,15 +10,85 @@
 // Go has figure that out, and has created a powerful [constants] systems that can be
 // used to higly improve parsing basic types.
 //
+// # Multi-line expressions
+//
+// Long expressions can be wrapped across several lines: line breaks are
+// allowed anywhere a space is, so both
+//
+//	2*d +
+//	    4*h
+//
+// and
+//
+//	2*d
+//	    + 4*h
+//
+// are valid. Line comments (// ...) are ignored. Line breaks are only
+// significant inside raw string literals.
+//
+// # Number literals
+//
+// Number literals follow Go's syntax, with one relaxation: hexadecimal,
+// octal and binary literals can have a fractional part without an exponent.
+// "0xFF.8" is 255.5 (Go requires "0xFF.8p0"), "0o7.4" is 7.5 and "0b1.1"
+// is 1.5. Literals with an exponent must follow Go's syntax: hexadecimal
+// ones use a 'p' exponent ("0x1.8p1" is 3), and octal or binary ones
+// cannot have one.
+//
+// # Functions
+//
+// On top of Go's builtin functions that apply to constants (real, imag,
+// complex, min, max, len), expressions can call:
+//
+//	sqrt(x)              square root of x
+//	hypot(p, q)          sqrt(p*p + q*q), without undue overflow
+//	dist(x1, y1, x2, y2) euclidean distance between (x1, y1) and (x2, y2)
+//	percentof(p, x)      p percent of x: percentof(25, 200) is 50
+//	pctchange(from, to)  relative change: pctchange(100, 150) is 0.5
+//	pow(x, y)            x to the power y, see also [Scope.WithPowerOperator]
+//	conj(z)              complex conjugate of z
+//	re(z), im(z)         real and imaginary parts of z, like real and imag
+//	abs(z)               absolute value of z, its magnitude if complex
+//	lookup(key, k1, v1, ..., [default])
+//	                     the value vi of the first key ki equal to key
+//	in(x, a, b, ...)     x == a || x == b || ...
+//
+// lookup compares keys of different kinds (strings and numbers) as
+// different. With an odd number of arguments after 'key', the last one is
+// the default value, returned when no key matches. With an even number, no
+// match is an error. in, unlike lookup, reports an error when comparing
+// values of different kinds, like Go does: "in(x, 1, 2.5)" is fine if x is
+// a number, but "in(x, 1, \"a\")" is always an error.
+//
+// percentof, pctchange, conj, re and im are exact, pctchange(0, x) is a
+// division by zero ([ErrDivByZero]). abs is exact for real numbers
+// (including complex numbers with a zero imaginary part), and computed like
+// hypot otherwise. pow is exact when y is an integer, unless the result is
+// huge (thousands of bits), and computed like [math.Pow] otherwise.
+//
+// sqrt, hypot and dist are computed in float64 precision: arguments are
+// rounded to the nearest float64, and the result is accurate to a few units
+// in the last place (hypot uses [math.Hypot]).
+//
+// When evaluated with [Scope.BigFloat], sqrt, hypot and dist are computed in
+// arbitrary precision instead.
+//
+// Identical calls in an expression are computed once: in
+// "sqrt(x) + sqrt(x)*2", sqrt(x) is computed once.
+//
 // [constants]: https://go.dev/blog/constants
 package calc
 
 import (
 	"fmt"
+	"go/ast"
 	"go/constant"
 	"go/token"
 	"go/types"
+	"maps"
 	"math"
+	"math/big"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -52,18 +122,249 @@ func String(expr string) (string, error) { return Scope{}.String(expr) }
 // zero type is valid.
 type Scope struct {
 	p *types.Package
+
+	math bool // auto-import the math namespace.
+	std  bool // standard library functions (math.Sqrt, strconv.Atoi, ...).
+
+	// source rewrites.
+	inputBase int  // base of integer literals without prefix, if not 0.
+	decimal   rune // alternative decimal separator.
+	si        bool // rewrite SI-suffixed numbers.
+	durations bool // rewrite duration-suffixed numbers.
+	byteSizes bool // rewrite byte size suffixed numbers.
+	implicit  bool // insert implicit multiplications.
+	mixed     bool // rewrite mixed numbers and exact fractions.
+	jimag     bool // rewrite j-suffixed imaginary numbers.
+
+	// syntax tree transformations.
+	chain    bool // rewrite chained comparisons.
+	caseless bool // case insensitive identifiers.
+	power    bool // rewrite the ** operator.
+
+	modulus int64   // modulus of integer arithmetic, if positive.
+	divMode DivMode // rounding of the integer division.
+
+	infPolicy InfPolicy // handling of infinite results of builtins.
+
+	disallowed map[token.Token]bool // operators that cannot be used.
+	operators  []operator           // custom binary operators.
+
+	funcs    map[string]*function // user-defined functions.
+	maxDepth int                  // maximum nesting of function calls, if positive.
+
+	rec   *recording // recorded changes, if any.
+	tally bool       // Load adds bare expressions to 'total'.
+
+	negIndex bool // Index and slice variables accept negative indices.
+
+	units   map[string]string // units of the variables that are quantities.
+	display map[string]string // display units of variables, other variables.
+
+	arrays map[string][]float64          // slice variables, only indexed.
+	maps   map[string]map[string]float64 // map variables, only indexed.
+
+	resolver func(name string) (any, bool) // resolves undefined identifiers.
+
+	costWeights *CostWeights // weights of Cost, if not the default ones.
+
+	budget *budget // evaluation time available, if limited.
+
+	preset Preset      // formatting of EvalRow, if set.
+	money  MoneyFormat // formatting of Money.
+
+	maxSteps int // maximum number of steps returned by EvalSteps, if positive.
+
+	maxDenom int64 // maximum denominator of rational results, if positive.
 }
 
 // eval expr in this Scope. nil value for 'p' is ok.
 func (s Scope) eval(expr string) (constant.Value, error) {
-	// c.main can be nil, and that is ok.
-	tv, err := types.Eval(token.NewFileSet(), s.p, token.NoPos, expr)
+	tv, err := s.check(expr)
 	if err != nil {
 		return nil, err
 	}
 	return tv.Value, nil
 }
 
+// check evaluates expr in this Scope and returns its type and value.
+func (s Scope) check(expr string) (types.TypeAndValue, error) {
+	return s.checkIn(s.newEvaluation(), expr)
+}
+
+// checkIn evaluates expr as part of the evaluation 'e'.
+func (s Scope) checkIn(e *evaluation, expr string) (tv types.TypeAndValue, err error) {
+	defer recoverPanic(&err, expr)
+	if s.budget != nil {
+		end, err := s.budget.begin(expr)
+		if err != nil {
+			return types.TypeAndValue{}, err
+		}
+		defer end()
+	}
+	src, err := s.rewrite(expr)
+	if err != nil {
+		return types.TypeAndValue{}, err
+	}
+	x, err := e.parse(expr, src)
+	if err != nil {
+		return types.TypeAndValue{}, err
+	}
+	if x, err = s.transform(e, x); err != nil {
+		return types.TypeAndValue{}, err
+	}
+	return e.checkConst(x)
+}
+
+// Parse parses 'expr' as s would before evaluating it, and returns its
+// syntax tree, along with the file set of its positions, for tooling like
+// syntax highlighting.
+//
+// The tree is parsed after the source rewrites enabled in s (decimal
+// separator, SI prefixes, ...), but before any other transformation: calls
+// to functions are still calls. Positions are relative to 'expr' when
+// rewrites preserved its length, and to the rewritten source otherwise.
+//
+// The returned tree is read-only: it is a fresh copy, changing it has no
+// effect on s.
+func (s Scope) Parse(expr string) (ast.Expr, *token.FileSet, error) {
+	src, err := s.rewrite(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	e := s.newEvaluation()
+	x, err := e.parse(expr, src)
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, e.fset, nil
+}
+
+// rewrite applies the source transformations enabled in s to expr.
+func (s Scope) rewrite(expr string) (string, error) {
+	expr = joinLines(expr)
+	if len(s.operators) > 0 {
+		expr = rewriteOperators(expr, s.operators)
+	}
+	expr = rewriteRadixFractions(expr)
+	if s.inputBase != 0 && s.inputBase != 10 {
+		expr = rewriteInputBase(expr, s.inputBase)
+	}
+	if s.decimal != 0 && s.decimal != '.' {
+		expr = rewriteDecimal(expr, s.decimal)
+	}
+	if s.mixed {
+		expr = rewriteMixed(expr)
+	}
+	if s.jimag {
+		expr = rewriteJImaginary(expr)
+	}
+	if s.byteSizes {
+		expr = rewriteByteSizes(expr)
+	}
+	if s.durations {
+		expr = rewriteDurations(expr)
+	}
+	if s.si {
+		expr = rewriteSI(expr)
+	}
+	if s.implicit {
+		expr = rewriteImplicit(expr)
+	}
+	return expr, nil
+}
+
+// transform applies the syntax tree transformations enabled in s to x.
+func (s Scope) transform(e *evaluation, x ast.Expr) (ast.Expr, error) {
+	pre := func(x ast.Expr) (ast.Expr, error) {
+		// Custom operators are restored first, the other transformations
+		// never see their proxies.
+		if len(s.operators) > 0 {
+			x = s.unproxy(x)
+		}
+		if err := s.allowed(e, x); err != nil {
+			return nil, err
+		}
+		var err error
+		if s.caseless {
+			if x, err = s.canonical(e, x); err != nil {
+				return nil, err
+			}
+		}
+		x = unnest(e, x)
+		if err := s.resolve(e, x); err != nil {
+			return nil, err
+		}
+		e.track(x)
+		if s.power {
+			x = unpower(x)
+		}
+		if s.chain {
+			if x, err = unchain(e, x); err != nil {
+				return nil, err
+			}
+		}
+		return s.expand(e, x)
+	}
+	// Calls to builtins are folded last, when their arguments are final.
+	post := func(x ast.Expr) (ast.Expr, error) {
+		e.unexpand(x)
+		orig := x
+		x, err := s.index(e, x)
+		if err != nil {
+			return nil, err
+		}
+		if x, err = s.operate(e, x); err != nil {
+			return nil, err
+		}
+		if x, err = e.call(x); err != nil {
+			return nil, err
+		}
+		if x, err = e.divide(x, s.divMode); err != nil {
+			return nil, err
+		}
+		if s.modulus != 0 {
+			x = reduce(x, s.modulus)
+		}
+		return x, e.step(orig, x)
+	}
+	return apply(x, pre, post)
+}
+
+// env returns the package expressions are evaluated in.
+//
+// It is the Scope's own package, unless some namespaces need to be
+// auto-imported, in which case it is a copy decorated with them.
+func (s Scope) env() *types.Package {
+	if !s.math {
+		return s.p
+	}
+	p := s.clone().p
+	// Insert does not replace existing objects: user's definitions win.
+	p.Scope().Insert(types.NewPkgName(token.NoPos, p, "math", mathLib.pack()))
+	return p
+}
+
+// clone returns a copy of s whose variables can be changed independently.
+func (s Scope) clone() Scope {
+	c := s
+	c.p = types.NewPackage("main", "main")
+	if s.p != nil {
+		for _, name := range s.p.Scope().Names() {
+			c.p.Scope().Insert(reown(s.p.Scope().Lookup(name), c.p))
+		}
+	}
+	return c
+}
+
+// reown returns 'obj', or a copy of it belonging to 'p' if it is an import:
+// the type checker requires imports to belong to the checked package.
+func reown(obj types.Object, p *types.Package) types.Object {
+	if pkg, ok := obj.(*types.PkgName); ok {
+		return types.NewPkgName(token.NoPos, p, pkg.Name(), pkg.Imported())
+	}
+	return obj
+}
+
 // return a non nil package.
 func (s *Scope) pack() *types.Package {
 	if s.p == nil {
@@ -79,32 +380,48 @@ func (s *Scope) assign(name string, tv types.TypeAndValue) {
 
 // Float64 evaluates 'expr' as a float64.
 func (s Scope) Float64(expr string) (float64, error) {
+	f, _, err := s.Float64WithExact(expr)
+	return f, err
+}
+
+// Float64WithExact evaluates 'expr' as a float64, and reports whether it is
+// exactly the value of the expression, or just the nearest float64.
+//
+// For instance "0.5" is exact, but "0.1" or "1/3." are not.
+func (s Scope) Float64WithExact(expr string) (f float64, exact bool, err error) {
 	val, err := s.eval(expr)
 	if err != nil {
-		return math.NaN(), err
+		return math.NaN(), false, err
 	}
 	// Force conversion to a constant.Float type (or Unknown)
 	fval := constant.ToFloat(val)
 	if fval.Kind() == constant.Unknown {
-		return math.NaN(), fmt.Errorf("not representable as a float (%v): %q", val.Kind(), expr)
+		return math.NaN(), false, fmt.Errorf("not representable as a float (%v): %q", val.Kind(), expr)
 	}
-	f, _ := constant.Float64Val(fval) // ignoring the bool about rounding
-	return f, nil
+	f, exact = constant.Float64Val(fval)
+	return f, exact, nil
 }
 
 // Float32  evaluates 'expr' as a float32.
 func (s Scope) Float32(expr string) (float32, error) {
+	f, _, err := s.Float32WithExact(expr)
+	return f, err
+}
+
+// Float32WithExact evaluates 'expr' as a float32, and reports whether it is
+// exactly the value of the expression, or just the nearest float32.
+func (s Scope) Float32WithExact(expr string) (f float32, exact bool, err error) {
 	val, err := s.eval(expr)
 	if err != nil {
-		return float32(math.NaN()), err
+		return float32(math.NaN()), false, err
 	}
 	// Force conversion to a constant.Float type (or Unknown)
 	fval := constant.ToFloat(val)
 	if fval.Kind() == constant.Unknown {
-		return float32(math.NaN()), fmt.Errorf("not representable as a float (%v): %q", val.Kind(), expr)
+		return float32(math.NaN()), false, fmt.Errorf("not representable as a float (%v): %q", val.Kind(), expr)
 	}
-	f, _ := constant.Float32Val(fval) // ignoring the bool about rounding
-	return f, nil
+	f, exact = constant.Float32Val(fval)
+	return f, exact, nil
 }
 
 // Complex128  evaluates 'expr' as a complex128.
@@ -156,11 +473,37 @@ func (s Scope) Int(expr string) (int64, error) {
 	}
 	i, ok := constant.Int64Val(ival)
 	if !ok {
-		return 0, fmt.Errorf("not exactly representable as an int64: %q", expr)
+		return 0, sentinelError{fmt.Errorf("not exactly representable as an int64: %q", expr), ErrIntOverflow}
 	}
 	return i, nil
 }
 
+// IntChecked is like [Scope.Int], but values beyond the range of int64 are
+// not an error: they wrap around, and 'overflowed' is true.
+//
+// The truncation rule is two's complement, like Go's conversions of
+// integers at runtime: only the 64 lowest bits of the value are kept. So
+// "1 << 63" is math.MinInt64, and "1 << 64" is 0, both overflowed.
+func (s Scope) IntChecked(expr string) (i int64, overflowed bool, err error) {
+	val, err := s.eval(expr)
+	if err != nil {
+		return 0, false, err
+	}
+	ival := constant.ToInt(val)
+	if ival.Kind() == constant.Unknown {
+		return 0, false, fmt.Errorf("not representable as an int (%v): %q", val.Kind(), expr)
+	}
+	if i, ok := constant.Int64Val(ival); ok {
+		return i, false, nil
+	}
+	b, ok := constant.Val(ival).(*big.Int)
+	if !ok {
+		return 0, false, fmt.Errorf("not representable as an int (%v): %q", val.Kind(), expr)
+	}
+	mask := new(big.Int).SetUint64(math.MaxUint64)
+	return int64(new(big.Int).And(b, mask).Uint64()), true, nil
+}
+
 // Uint evaluates 'expr' as an int64.
 func (s Scope) Uint(expr string) (uint64, error) {
 	val, err := s.eval(expr)
@@ -203,14 +546,89 @@ func (s Scope) String(expr string) (string, error) {
 	return constant.StringVal(val), nil
 }
 
+// CompareStrings evaluates the string expressions 'a' and 'b', and compares
+// them lexicographically, byte-wise like [strings.Compare]: the result is 0
+// if a == b, -1 if a < b, and +1 if a > b.
+func (s Scope) CompareStrings(a, b string) (int, error) {
+	x, err := s.String(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := s.String(b)
+	if err != nil {
+		return 0, err
+	}
+	return strings.Compare(x, y), nil
+}
+
+// Eval evaluates 'expr' as a constant of any kind.
+func (s Scope) Eval(expr string) (constant.Value, error) { return s.eval(expr) }
+
+// EvalHistory is like [Scope.Eval], but it also stores the result in the
+// variable 'ans', so that the next expression can refer to it: "ans * 2".
+//
+// 'ans' is an ordinary variable: it is listed by [Scope.Names], and it can
+// be read, assigned or deleted like any other. EvalHistory replaces it
+// unconditionally, even if it was set explicitly, like [Scope.Set] does.
+// So an explicit Assign("ans", ...) only lasts until the next call to
+// EvalHistory. When 'expr' fails, 'ans' is not changed.
+//
+// Go reserves '_' as the blank identifier, it cannot be used as an alias.
+func (s *Scope) EvalHistory(expr string) (constant.Value, error) {
+	tv, err := s.check(expr)
+	if err != nil {
+		return nil, err
+	}
+	s.Delete("ans")
+	s.assign("ans", tv)
+	return tv.Value, nil
+}
+
 // Assign evaluates 'expr' and assign its value to the variable 'name'.
 //
 // If the variable 'name' already exists, its value is not changed.
 func (s *Scope) Assign(name, expr string) error {
-	tv, err := types.Eval(token.NewFileSet(), s.p, token.NoPos, expr)
+	tv, err := s.check(expr)
 	if err != nil {
 		return err
 	}
+	if s.p == nil || s.p.Scope().Lookup(name) == nil {
+		s.record("%s = %s", name, line(expr))
+	}
+	s.assign(name, tv)
+	return nil
+}
+
+// CheckAssign reports the error [Scope.Assign] would return, without
+// changing s.
+//
+// It also reports an error if 'name' is not a valid identifier, or if it is
+// the name of an import, in which case Assign would have no effect.
+func (s Scope) CheckAssign(name, expr string) error {
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	if s.p != nil {
+		if _, ok := s.p.Scope().Lookup(name).(*types.PkgName); ok {
+			return fmt.Errorf("%s is an import", name)
+		}
+	}
+	_, err := s.check(expr)
+	return err
+}
+
+// Set evaluates 'expr' and assign its value to the variable 'name'.
+//
+// Unlike [Scope.Assign], if the variable 'name' already exists, its value
+// is replaced. 'expr' is evaluated before the replacement, so it can refer
+// to the previous value of 'name'.
+func (s *Scope) Set(name, expr string) error {
+	tv, err := s.check(expr)
+	if err != nil {
+		return err
+	}
+	s.record("%s = %s", name, line(expr))
+	s.Delete(name)
 	s.assign(name, tv)
 	return nil
 }
@@ -237,96 +655,75 @@ func (s *Scope) Assign(name, expr string) error {
 //
 // If the variable 'name' already exists, its value is not changed.
 func (s *Scope) AssignValue(name string, v any) {
+	val, t, ok := constantOf(v)
+	if !ok {
+		panic(fmt.Sprintf("unsupported type %T", v))
+	}
+	s.assign(name, types.TypeAndValue{
+		Type:  untypedOf(t),
+		Value: val,
+	})
+}
+
+// constantOf returns the constant value of the runtime value 'v', and its
+// Go type.
+func constantOf(v any) (constant.Value, *types.Basic, bool) {
 	switch o := v.(type) {
 	case float64:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedFloat],
-			Value: constant.MakeFloat64(o),
-		})
+		return constant.MakeFloat64(o), types.Typ[types.Float64], true
 	case float32:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedFloat],
-			Value: constant.MakeFloat64(float64(o)),
-		})
+		return constant.MakeFloat64(float64(o)), types.Typ[types.Float32], true
 	case complex128:
 		x := constant.MakeFloat64(real(o))
 		y := constant.MakeFloat64(imag(o))
-
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedComplex],
-			Value: constant.BinaryOp(x, token.ADD, constant.MakeImag(y)),
-		})
+		return constant.BinaryOp(x, token.ADD, constant.MakeImag(y)), types.Typ[types.Complex128], true
 	case complex64:
 		x := constant.MakeFloat64(float64(real(o)))
 		y := constant.MakeFloat64(float64(imag(o)))
-
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedComplex],
-			Value: constant.BinaryOp(x, token.ADD, constant.MakeImag(y)),
-		})
+		return constant.BinaryOp(x, token.ADD, constant.MakeImag(y)), types.Typ[types.Complex64], true
 	case int64:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeInt64(o),
-		})
+		return constant.MakeInt64(o), types.Typ[types.Int64], true
 	case int32:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeInt64(int64(o)),
-		})
+		return constant.MakeInt64(int64(o)), types.Typ[types.Int32], true
 	case int16:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeInt64(int64(o)),
-		})
+		return constant.MakeInt64(int64(o)), types.Typ[types.Int16], true
 	case int8:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeInt64(int64(o)),
-		})
+		return constant.MakeInt64(int64(o)), types.Typ[types.Int8], true
 	case int:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeInt64(int64(o)),
-		})
+		return constant.MakeInt64(int64(o)), types.Typ[types.Int], true
 	case uint64:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeUint64(o),
-		})
+		return constant.MakeUint64(o), types.Typ[types.Uint64], true
 	case uint32:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeUint64(uint64(o)),
-		})
+		return constant.MakeUint64(uint64(o)), types.Typ[types.Uint32], true
 	case uint16:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeUint64(uint64(o)),
-		})
+		return constant.MakeUint64(uint64(o)), types.Typ[types.Uint16], true
 	case uint8:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeUint64(uint64(o)),
-		})
+		return constant.MakeUint64(uint64(o)), types.Typ[types.Uint8], true
 	case uint:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedInt],
-			Value: constant.MakeUint64(uint64(o)),
-		})
+		return constant.MakeUint64(uint64(o)), types.Typ[types.Uint], true
 	case bool:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedBool],
-			Value: constant.MakeBool(o),
-		})
+		return constant.MakeBool(o), types.Typ[types.Bool], true
 	case string:
-		s.assign(name, types.TypeAndValue{
-			Type:  types.Typ[types.UntypedString],
-			Value: constant.MakeString(o),
-		})
-	default:
-		panic(fmt.Sprintf("unsupported type %T", v))
+		return constant.MakeString(o), types.Typ[types.String], true
 	}
+	return nil, nil, false
+}
+
+// untypedOf returns the untyped counterpart of the basic type 't'.
+func untypedOf(t *types.Basic) *types.Basic {
+	switch info := t.Info(); {
+	case info&types.IsBoolean != 0:
+		return types.Typ[types.UntypedBool]
+	case info&types.IsString != 0:
+		return types.Typ[types.UntypedString]
+	case info&types.IsInteger != 0:
+		return types.Typ[types.UntypedInt]
+	case info&types.IsFloat != 0:
+		return types.Typ[types.UntypedFloat]
+	case info&types.IsComplex != 0:
+		return types.Typ[types.UntypedComplex]
+	}
+	return types.Typ[types.Invalid]
 }
 
 // Import another [Scope] inside this one.
@@ -343,5 +740,109 @@ func (s *Scope) Import(name string, lib *Scope) error {
 	}
 	pkgName := types.NewPkgName(token.NoPos, s.pack(), name, lib.pack())
 	s.pack().Scope().Insert(pkgName)
+	s.record("import %s", name)
 	return nil
 }
+
+// ImportUnder imports 'lib' inside s like [Scope.Import], but under the
+// namespace 'root': its exposed variables are referenced as
+// `<root>.<name>.<var>`, so that many scopes can be organized in a tree,
+// like "units.si.K" and "units.us.Mile".
+//
+// 'root' itself is not a scope: it only exists through the scopes imported
+// under it. Scopes imported under 'root', and a variable or an import named
+// 'root', coexist: `<root>.<name>` is the scope imported under 'root' as
+// 'name', if any, and otherwise refers to the variable or import 'root'.
+// Like [Scope.Import], importing a name that already exists under 'root'
+// does not replace it.
+//
+// An error is returned if 'root' or 'name' is exported.
+func (s *Scope) ImportUnder(root, name string, lib *Scope) error {
+	for _, n := range []string{root, name} {
+		if ch, _ := utf8.DecodeRuneInString(n); unicode.IsUpper(ch) {
+			return fmt.Errorf("package names cannot be exported: %v", n)
+		}
+	}
+	pkgName := types.NewPkgName(token.NoPos, s.pack(), root+"."+name, lib.pack())
+	s.pack().Scope().Insert(pkgName)
+	s.record("import %s.%s", root, name)
+	return nil
+}
+
+// unnest rewrites the selector 'x' of a variable of a scope imported under
+// a root namespace, "root.name.Var", into a selector of the import itself,
+// named "root.name" in e.
+func unnest(e *evaluation, x ast.Expr) ast.Expr {
+	sel, ok := x.(*ast.SelectorExpr)
+	if !ok || e.pkg == nil {
+		return x
+	}
+	inner, ok := sel.X.(*ast.SelectorExpr)
+	if !ok {
+		return x
+	}
+	root, ok := inner.X.(*ast.Ident)
+	if !ok {
+		return x
+	}
+	name := root.Name + "." + inner.Sel.Name
+	if _, ok := e.pkg.Scope().Lookup(name).(*types.PkgName); !ok {
+		return x
+	}
+	return &ast.SelectorExpr{X: &ast.Ident{NamePos: root.NamePos, Name: name}, Sel: sel.Sel}
+}
+
+// Names returns the sorted names of the variables defined in s.
+//
+// Imported scopes are not included.
+func (s Scope) Names() []string {
+	if s.p == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range s.p.Scope().Names() {
+		if _, ok := s.p.Scope().Lookup(name).(*types.Const); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Get returns the value of the variable 'name', if it exists.
+func (s Scope) Get(name string) (constant.Value, bool) {
+	if s.p == nil {
+		return nil, false
+	}
+	c, ok := s.p.Scope().Lookup(name).(*types.Const)
+	if !ok {
+		return nil, false
+	}
+	return c.Val(), true
+}
+
+// Delete removes the variable, the slice or map variable, or the import
+// 'name' from s, if it exists.
+//
+// Copies of s, and Scopes that have imported s, are not affected.
+func (s *Scope) Delete(name string) {
+	s.deleteSlice(name)
+	s.deleteMap(name)
+	if _, ok := s.units[name]; ok {
+		s.units = maps.Clone(s.units)
+		delete(s.units, name)
+	}
+	if _, ok := s.display[name]; ok {
+		s.display = maps.Clone(s.display)
+		delete(s.display, name)
+	}
+	if s.p == nil || s.p.Scope().Lookup(name) == nil {
+		return
+	}
+	p := types.NewPackage("main", "main")
+	for _, n := range s.p.Scope().Names() {
+		if n != name {
+			p.Scope().Insert(reown(s.p.Scope().Lookup(n), p))
+		}
+	}
+	s.p = p
+}
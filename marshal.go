@@ -0,0 +1,125 @@
+package calc
+
+import (
+	"fmt"
+	"go/constant"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MarshalText implements [encoding.TextMarshaler]: it encodes the
+// variables of s as a script, one "name = value" statement per line, in
+// the order of [Scope.Names]:
+//
+//	c = 299792458
+//	third = (1/3.0)
+//	x = int8(-1)
+//
+// Values are exact, and typed variables keep their type. Imports and
+// user-defined functions are not encoded.
+func (s Scope) MarshalText() ([]byte, error) {
+	var b strings.Builder
+	for _, name := range s.Names() {
+		c := s.p.Scope().Lookup(name).(*types.Const)
+		fmt.Fprintf(&b, "%s = %s\n", name, exactLiteral(c.Type(), c.Val()))
+	}
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]: it sets the
+// variables encoded by [Scope.MarshalText] in s, replacing the existing
+// ones with the same name.
+//
+// The text is read like by [Scope.Load], but in a Scope without options,
+// so that the options of s (input base, decimal separator, ...) do not
+// change the values. Nothing is changed in s if the text is invalid.
+func (s *Scope) UnmarshalText(text []byte) error {
+	var c Scope
+	if err := c.Load(string(text), nil); err != nil {
+		return err
+	}
+	for _, name := range c.Names() {
+		obj := c.p.Scope().Lookup(name)
+		s.Delete(name)
+		s.assign(name, types.TypeAndValue{Type: obj.Type(), Value: obj.(*types.Const).Val()})
+	}
+	return nil
+}
+
+// exactLiteral returns an expression of the exact value 'v' of type 't'.
+func exactLiteral(t types.Type, v constant.Value) string {
+	var lit string
+	switch v.Kind() {
+	case constant.String:
+		lit = strconv.Quote(constant.StringVal(v))
+	case constant.Float:
+		r, _ := toRat(v)
+		if r.IsInt() {
+			lit = r.Num().String() + ".0"
+		} else {
+			lit = "(" + r.Num().String() + "/" + r.Denom().String() + ".0)"
+		}
+	case constant.Complex:
+		re := exactLiteral(types.Typ[types.UntypedFloat], constant.ToFloat(constant.Real(v)))
+		im := exactLiteral(types.Typ[types.UntypedFloat], constant.ToFloat(constant.Imag(v)))
+		lit = "complex(" + re + ", " + im + ")"
+	default:
+		lit = v.ExactString()
+	}
+	if b, ok := t.(*types.Basic); ok && b.Info()&types.IsUntyped != 0 {
+		return lit
+	}
+	return t.String() + "(" + lit + ")"
+}
+
+// SaveFile saves the variables of s in the file 'path', encoded with
+// [Scope.MarshalText], so that [LoadFile] can restore them.
+//
+// The file is replaced atomically: it is written to a temporary file in the
+// same directory, then renamed, so that it is never left half-written.
+func (s *Scope) SaveFile(path string) error {
+	text, err := s.MarshalText()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // after a successful rename, there is nothing to remove.
+	if _, err := f.Write(text); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadFile returns a new Scope with the variables saved in the file 'path'
+// by [Scope.SaveFile].
+//
+// A missing file is not an error: it is an empty Scope, as if nothing had
+// been saved yet.
+func LoadFile(path string) (*Scope, error) {
+	s := new(Scope)
+	text, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.UnmarshalText(text); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
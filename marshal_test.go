@@ -0,0 +1,88 @@
+package calc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarshalText(t *testing.T) {
+	var s Scope
+	s.Assign("c", "299792458")
+	s.Assign("third", "1/3.")
+	s.Assign("two", "2.0")
+	s.Assign("big", "1e100 + 1")
+	s.Assign("z", "1.5 - 1i/3")
+	s.Assign("msg", `"a\tb"`)
+	s.Assign("ok", "1 < 2")
+	s.AssignTyped("x", int8(-1))
+	s.AssignTyped("f", float32(0.1))
+	var lib Scope
+	s.Import("lib", &lib)
+
+	text, err := s.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() unexpected error: %v", err)
+	}
+	want := `big = 10000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001.0
+c = 299792458
+f = float32((13421773/134217728.0))
+msg = "a\tb"
+ok = true
+third = (1/3.0)
+two = 2.0
+x = int8(-1)
+z = complex((3/2.0), (-1/3.0))
+`
+	if string(text) != want {
+		t.Errorf("MarshalText() = %s, want %s", text, want)
+	}
+
+	c := Scope{}.WithInputBase(16)
+	c.Assign("c", "1")
+	if err := c.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText() unexpected error: %v", err)
+	}
+	s.Delete("lib")
+	if !c.EqualScope(s) {
+		t.Errorf("UnmarshalText() = %v, want %v", c.Names(), s.Names())
+	}
+	if err := c.UnmarshalText([]byte("a = 1\nb = ")); err == nil {
+		t.Errorf("UnmarshalText() expected an error")
+	}
+}
+
+func TestSaveFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vars.calc")
+	s, err := LoadFile(path)
+	if err != nil || len(s.Names()) != 0 {
+		t.Fatalf("LoadFile() of a missing file = %v, %v, want an empty scope", s.Names(), err)
+	}
+	s.Assign("x", "1/3.")
+	s.Assign("n", "42")
+	if err := s.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() unexpected error: %v", err)
+	}
+	s.Set("n", "43")
+	if err := s.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() unexpected error: %v", err)
+	}
+	got, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() unexpected error: %v", err)
+	}
+	if !got.EqualScope(*s) {
+		t.Errorf("LoadFile() = %v, want %v", got.Names(), s.Names())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("SaveFile() left %d files, want 1", len(entries))
+	}
+
+	if err := os.WriteFile(path, []byte("x = "), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Errorf("LoadFile() of an invalid file expected an error")
+	}
+}
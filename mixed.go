@@ -0,0 +1,55 @@
+package calc
+
+import (
+	"go/token"
+	"math/big"
+	"strings"
+)
+
+// Mixed evaluates 'expr' as an exact rational, where fractions can be
+// written as mixed numbers, like in recipes: "1 1/2" is 3/2, and
+// "-2 3/4" is -11/4.
+//
+// A mixed number is an integer followed, after spaces, by a fraction of
+// integers. Fractions of integer literals are exact, unlike in Go where
+// "1/2" is the integer division: "1 1/2 + 1/2" is 2. Other expressions
+// follow Go's rules.
+func (s Scope) Mixed(expr string) (*big.Rat, error) {
+	s.mixed = true
+	r, err := s.rat(expr)
+	if err != nil {
+		return nil, err
+	}
+	return s.limit(r), nil
+}
+
+// rewriteMixed rewrites mixed numbers "w n/d" into "(w + n/d.0)", and
+// fractions "n/d" into "n/d.0".
+func rewriteMixed(expr string) string {
+	lexemes := scan(expr)
+	isInt := func(i int) bool {
+		return i >= 0 && i < len(lexemes) && lexemes[i].tok == token.INT && isDecimal(lexemes[i].lit)
+	}
+	var b strings.Builder
+	last := 0
+	for i := 0; i+2 < len(lexemes); i++ {
+		num, quo, den := lexemes[i], lexemes[i+1], lexemes[i+2]
+		if !isInt(i) || quo.tok != token.QUO || !isInt(i+2) || len(den.lit) > 1 && den.lit[0] == '0' {
+			continue
+		}
+		// The whole part must not be the denominator of a previous fraction:
+		// "1/2 3/4" is two fractions, and an error.
+		if isInt(i-1) && lexemes[i-1].off >= last {
+			whole := lexemes[i-1]
+			b.WriteString(expr[last:whole.off])
+			b.WriteString("(" + whole.lit + " + " + num.lit + "/" + den.lit + ".0)")
+		} else {
+			b.WriteString(expr[last:den.end])
+			b.WriteString(".0")
+		}
+		last = den.end
+		i += 2
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
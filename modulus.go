@@ -0,0 +1,62 @@
+package calc
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+// WithModulus returns a copy of s where integer arithmetic is modulo 'n':
+// with n = 7, "5 * 5" is 4, and "-1" is 6.
+//
+// Integer literals, and the result of each +, -, *, / and << operation, and
+// of unary -, are reduced into [0, n) before being used. So intermediate
+// values stay small, and "3 - 5" is 5, not -2. Variables are used as is,
+// but any operation on them is reduced: with x = 10, "x" is 10 but "+x" or
+// "x*1" is 3. The division
+// is Go's integer division of the reduced operands, not a modular inverse.
+//
+// Operands must be integers: "%" is not defined on floats, so "2.5 + 1" is
+// an error. Comparisons are made on reduced values: "5 * 5 == 4" is true.
+//
+// If n is not positive, arithmetic is not modular.
+func (s Scope) WithModulus(n int64) Scope {
+	s.modulus = max(n, 0)
+	return s
+}
+
+// reduce wraps 'x' into "((x % n) + n) % n" if it is an integer literal or
+// an arithmetic operation.
+func reduce(x ast.Expr, n int64) ast.Expr {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.INT {
+			return x
+		}
+	case *ast.BinaryExpr:
+		switch x.Op {
+		case token.ADD, token.SUB, token.MUL, token.QUO, token.SHL:
+		default:
+			return x
+		}
+	case *ast.UnaryExpr:
+		if x.Op != token.SUB && x.Op != token.ADD {
+			return x
+		}
+	default:
+		return x
+	}
+	return mod(mod(x, n, token.ADD), n, token.REM)
+}
+
+// mod returns "(x % n) op n", or "(x % n)" if op is token.REM.
+func mod(x ast.Expr, n int64, op token.Token) ast.Expr {
+	lit := func() ast.Expr {
+		return &ast.BasicLit{ValuePos: x.Pos(), Kind: token.INT, Value: strconv.FormatInt(n, 10)}
+	}
+	var y ast.Expr = &ast.BinaryExpr{X: x, OpPos: x.Pos(), Op: token.REM, Y: lit()}
+	if op != token.REM {
+		y = &ast.BinaryExpr{X: y, OpPos: x.Pos(), Op: op, Y: lit()}
+	}
+	return &ast.ParenExpr{Lparen: x.Pos(), X: y, Rparen: x.End()}
+}
@@ -0,0 +1,60 @@
+package calc
+
+import "strings"
+
+// MoneyFormat is how [Scope.Money] formats amounts. The zero value formats
+// like "-$1,234.50".
+type MoneyFormat struct {
+	Thousands rune // separator of the groups of three digits, ',' if 0.
+	Decimal   rune // separator of the decimals, '.' if 0.
+
+	// Parentheses formats negative amounts like "($5.00)", instead of
+	// "-$5.00".
+	Parentheses bool
+}
+
+// WithMoneyFormat returns a copy of s where [Scope.Money] formats amounts
+// with 'f', for instance with the separators of a locale: with Thousands
+// '.' and Decimal ',', 1234.5 is "€1.234,50".
+func (s Scope) WithMoneyFormat(f MoneyFormat) Scope {
+	s.money = f
+	return s
+}
+
+// Money evaluates 'expr' exactly, rounds it to 'places' decimal places like
+// [Scope.RoundTo] does (half to even), and formats it as an amount of
+// currency, prefixed with 'symbol' and with thousands separators:
+// Money("19.99 * 3", "$", 2) is "$59.97".
+//
+// See [Scope.WithMoneyFormat] to change the separators, or the notation of
+// negative amounts.
+func (s Scope) Money(expr, symbol string, places int) (string, error) {
+	r, err := s.RoundTo(expr, places)
+	if err != nil {
+		return "", err
+	}
+	thousands, decimal := s.money.Thousands, s.money.Decimal
+	if thousands == 0 {
+		thousands = ','
+	}
+	if decimal == 0 {
+		decimal = '.'
+	}
+	str := r.FloatString(max(places, 0))
+	if r.Sign() < 0 {
+		str = str[1:]
+	}
+	whole, decimals, ok := strings.Cut(str, ".")
+	str = symbol + groupThousands(whole, thousands)
+	if ok {
+		str += string(decimal) + decimals
+	}
+	switch {
+	case r.Sign() >= 0:
+		return str, nil
+	case s.money.Parentheses:
+		return "(" + str + ")", nil
+	default:
+		return "-" + str, nil
+	}
+}
@@ -0,0 +1,37 @@
+package calc
+
+import "testing"
+
+func TestMoney(t *testing.T) {
+	euro := Scope{}.WithMoneyFormat(MoneyFormat{Thousands: '.', Decimal: ','})
+	paren := Scope{}.WithMoneyFormat(MoneyFormat{Parentheses: true})
+	tests := []struct {
+		s      Scope
+		expr   string
+		symbol string
+		places int
+		want   string
+	}{
+		{Scope{}, "19.99 * 3", "$", 2, "$59.97"},
+		{Scope{}, "1234567.125", "$", 2, "$1,234,567.12"},
+		{Scope{}, "-5", "$", 2, "-$5.00"},
+		{Scope{}, "999.5", "¥", 0, "¥1,000"},
+		{Scope{}, "0.0015", "", 3, "0.002"},
+		{euro, "1234.5", "€", 2, "€1.234,50"},
+		{paren, "-1234.5", "$", 2, "($1,234.50)"},
+		{paren, "5", "$", 2, "$5.00"},
+	}
+	for _, test := range tests {
+		got, err := test.s.Money(test.expr, test.symbol, test.places)
+		if err != nil {
+			t.Errorf("Money(%q, %q, %d) unexpected error: %v", test.expr, test.symbol, test.places, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Money(%q, %q, %d) = %q, want %q", test.expr, test.symbol, test.places, got, test.want)
+		}
+	}
+	if _, err := (Scope{}).Money("1i", "$", 2); err == nil {
+		t.Errorf("Money(\"1i\") expected an error")
+	}
+}
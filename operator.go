@@ -0,0 +1,210 @@
+package calc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// operator is a binary operator registered with RegisterOperator.
+type operator struct {
+	symbol string
+	fn     func(a, b constant.Value) (constant.Value, error)
+	prec   int
+}
+
+// proxies are the Go operators standing for the custom operators of each
+// precedence.
+var proxies = [...]token.Token{1: token.LOR, 2: token.LAND, 3: token.EQL, 4: token.ADD, 5: token.MUL}
+
+// RegisterOperator adds the binary operator 'symbol' to s, computed by
+// 'fn', for instance "//" for the floor division:
+//
+//	s.RegisterOperator("//", floorDiv, 5)
+//
+// 'prec' is the precedence of the operator, on Go's scale: 5 binds like
+// '*', 4 like '+', 3 like '==', 2 like '&&' and 1 like '||'. Operators are
+// left-associative, like Go's, and unary operators bind tighter: "a // -b"
+// is "a // (-b)". Operands are evaluated before 'fn' is called, and errors
+// of 'fn' are evaluation errors.
+//
+// 'symbol' is recognized anywhere outside string literals, even inside
+// longer operators: it must be made of punctuation, and must not be one of
+// Go's operators. If 'symbol' is already registered, it is replaced.
+//
+// RegisterOperator panics if 'symbol' or 'prec' is invalid.
+func (s *Scope) RegisterOperator(symbol string, fn func(a, b constant.Value) (constant.Value, error), prec int) {
+	if prec < 1 || prec >= len(proxies) {
+		panic(fmt.Sprintf("invalid operator precedence %d", prec))
+	}
+	if !isOperatorSymbol(symbol) {
+		panic(fmt.Sprintf("invalid operator symbol %q", symbol))
+	}
+	op := operator{symbol: symbol, fn: fn, prec: prec}
+	s.operators = slices.Clone(s.operators) // copies of s are not changed.
+	if i := slices.IndexFunc(s.operators, func(op operator) bool { return op.symbol == symbol }); i >= 0 {
+		s.operators[i] = op
+		return
+	}
+	s.operators = append(s.operators, op)
+}
+
+// isOperatorSymbol reports whether 'symbol' can be a custom operator.
+func isOperatorSymbol(symbol string) bool {
+	if symbol == "" || strings.ContainsAny(symbol, "\"'`()[]{},;._") {
+		return false
+	}
+	for _, r := range symbol {
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			return false
+		}
+	}
+	for tok := token.ADD; tok <= token.TILDE; tok++ {
+		if tok.String() == symbol {
+			return false
+		}
+	}
+	return true
+}
+
+// rewriteOperators rewrites the custom operators in 'expr' into their
+// proxies: the i-th operator is the Go operator of its precedence, followed
+// by i+1 receive operators "<-", which are not valid in constant
+// expressions. So "a // b" is "a * <-b", parsed with the precedence of '*',
+// and rewritten by unproxy.
+func rewriteOperators(expr string, ops []operator) string {
+	var b strings.Builder
+	for i := 0; i < len(expr); {
+		if end := literalEnd(expr, i); end > i {
+			b.WriteString(expr[i:end])
+			i = end
+			continue
+		}
+		found := -1
+		for j, op := range ops {
+			if strings.HasPrefix(expr[i:], op.symbol) && (found < 0 || len(op.symbol) > len(ops[found].symbol)) {
+				found = j
+			}
+		}
+		if found < 0 {
+			b.WriteByte(expr[i])
+			i++
+			continue
+		}
+		op := ops[found]
+		fmt.Fprintf(&b, " %s %s", proxies[op.prec], strings.Repeat("<-", found+1))
+		i += len(op.symbol)
+	}
+	return b.String()
+}
+
+// literalEnd returns the end of the string or rune literal starting at 'i'
+// in 'expr', or 'i' if there is none.
+func literalEnd(expr string, i int) int {
+	quote := expr[i]
+	if quote != '"' && quote != '\'' && quote != '`' {
+		return i
+	}
+	for j := i + 1; j < len(expr); j++ {
+		switch {
+		case expr[j] == '\\' && quote != '`':
+			j++
+		case expr[j] == quote:
+			return j + 1
+		}
+	}
+	return len(expr)
+}
+
+// unproxy rewrites 'x' into a call to the custom operator of s it is the
+// proxy of, if any: "a * <-b" is a call to "//" with the arguments a and b.
+//
+// The receive operators apply to the leftmost operand of the right-hand
+// side: "a @ b * c", with '@' binding like '+', is parsed as
+// "a + (<-b * c)".
+func (s Scope) unproxy(x ast.Expr) ast.Expr {
+	b, ok := x.(*ast.BinaryExpr)
+	if !ok {
+		return x
+	}
+	// Find the leftmost operand of the right-hand side.
+	left := &b.Y
+	for {
+		y, ok := (*left).(*ast.BinaryExpr)
+		if !ok || y.Op.Precedence() <= b.Op.Precedence() {
+			break
+		}
+		left = &y.X
+	}
+	n := 0
+	operand := *left
+	for u, ok := operand.(*ast.UnaryExpr); ok && u.Op == token.ARROW; u, ok = operand.(*ast.UnaryExpr) {
+		operand, n = u.X, n+1
+	}
+	if n == 0 || n > len(s.operators) || b.Op != proxies[s.operators[n-1].prec] {
+		return x
+	}
+	*left = operand
+	return &ast.CallExpr{
+		Fun:    &ast.Ident{NamePos: b.OpPos, Name: s.operators[n-1].symbol},
+		Lparen: b.OpPos,
+		Args:   []ast.Expr{b.X, b.Y},
+		Rparen: b.End(),
+	}
+}
+
+// operate folds 'x' into a temporary variable holding its result, if it is
+// a call to a custom operator of s.
+//
+// It must be called on the operands first, so they are constant already.
+func (s Scope) operate(e *evaluation, x ast.Expr) (ast.Expr, error) {
+	call, ok := x.(*ast.CallExpr)
+	if !ok {
+		return x, nil
+	}
+	id, ok := call.Fun.(*ast.Ident)
+	if !ok {
+		return x, nil
+	}
+	i := slices.IndexFunc(s.operators, func(op operator) bool { return op.symbol == id.Name })
+	if i < 0 {
+		return x, nil
+	}
+	fn := s.operators[i].fn
+	name := types.ExprString(call.Args[0]) + " " + id.Name + " " + types.ExprString(call.Args[1])
+	a, err := e.value(call.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	b, err := e.value(call.Args[1])
+	if err != nil {
+		return nil, err
+	}
+	v, err := fn(a, b)
+	if err == nil && (v == nil || v.Kind() == constant.Unknown) {
+		err = fmt.Errorf("no value")
+	}
+	if err != nil {
+		return nil, e.errorf(id.Pos(), "%s: %v", name, err)
+	}
+	return e.bind(&ast.Ident{NamePos: call.Pos(), Name: name}, types.TypeAndValue{Type: untyped(v), Value: v}), nil
+}
+
+// exprString is like [types.ExprString], but writes the custom operators
+// of s as they are written, not as their proxies.
+func (s Scope) exprString(x ast.Expr) string {
+	str := types.ExprString(x)
+	// The proxies with the most receive operators first, as the others
+	// are their prefixes.
+	for i := len(s.operators) - 1; i >= 0; i-- {
+		op := s.operators[i]
+		proxy := " " + proxies[op.prec].String() + " " + strings.Repeat("<-", i+1)
+		str = strings.ReplaceAll(str, proxy, " "+op.symbol+" ")
+	}
+	return str
+}
@@ -0,0 +1,93 @@
+package calc
+
+import (
+	"errors"
+	"go/constant"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegisterOperator(t *testing.T) {
+	floorDiv := func(a, b constant.Value) (constant.Value, error) {
+		if constant.Sign(b) == 0 {
+			return nil, errors.New("division by zero")
+		}
+		q := constant.BinaryOp(a, token.QUO_ASSIGN, b)
+		if r := constant.BinaryOp(a, token.REM, b); constant.Sign(r) != 0 && constant.Sign(r) != constant.Sign(b) {
+			q = constant.BinaryOp(q, token.SUB, constant.MakeInt64(1))
+		}
+		return q, nil
+	}
+	concat := func(a, b constant.Value) (constant.Value, error) {
+		return constant.MakeString(a.ExactString() + b.ExactString()), nil
+	}
+	var s Scope
+	s.Assign("x", "7")
+	s.RegisterOperator("//", floorDiv, 5)
+	s.RegisterOperator("<>", func(a, b constant.Value) (constant.Value, error) {
+		return constant.MakeBool(constant.Compare(a, token.NEQ, b)), nil
+	}, 3)
+	s.RegisterOperator("@", concat, 4)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"x // 2", "3"},
+		{"-x // 2", "-4"},
+		{"x // -2", "-4"},
+		{"1 + x // 2 * 3", "10"},
+		{"100 // 10 // 3", "3"},
+		{"x//(1+1)", "3"},
+		{"x // 2 <> 3", "false"},
+		{"1 + 2 @ 3 * 4", `"312"`},
+		{`len("a // b") // 2`, "3"},
+	}
+	for _, test := range tests {
+		v, err := s.Eval(test.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got := v.ExactString(); got != test.want {
+			t.Errorf("Eval(%q) = %s, want %s", test.expr, got, test.want)
+		}
+	}
+	if _, err := s.Eval("x // 0"); err == nil || !strings.Contains(err.Error(), "x // 0: division by zero") {
+		t.Errorf("Eval(\"x // 0\") error = %v, want a division by zero", err)
+	}
+
+	for _, symbol := range []string{"", "+", "&^", "a", "_", "(("} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterOperator(%q) expected a panic", symbol)
+				}
+			}()
+			s.RegisterOperator(symbol, concat, 4)
+		}()
+	}
+
+	// Other options never see the proxies of custom operators.
+	if v, err := s.WithDisallowedOps(token.MUL, token.ARROW).Eval("x // 2"); err != nil || v.ExactString() != "3" {
+		t.Errorf("Eval(\"x // 2\") with * disallowed = %v, %v, want 3", v, err)
+	}
+	var asked []string
+	resolver := func(name string) (any, bool) {
+		asked = append(asked, name)
+		return 8, true
+	}
+	if v, err := s.WithResolver(resolver).Eval("y // 3"); err != nil || v.ExactString() != "2" || len(asked) != 1 || asked[0] != "y" {
+		t.Errorf("Eval(\"y // 3\") with a resolver = %v, %v, asked %q, want 2, asked [y]", v, err, asked)
+	}
+	c, err := s.Compile("x // 2 == 3 && x @ 1 <> \"71\"")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+	_, log, err := c.MatchVerbose(nil)
+	if want := []string{"x // 2 == 3: true", `x @ 1 <> "71": false`}; err != nil || !slices.Equal(log, want) {
+		t.Errorf("MatchVerbose() = %q, %v, want %q", log, err, want)
+	}
+}
@@ -0,0 +1,48 @@
+package calc
+
+import (
+	"go/ast"
+	"go/token"
+	"maps"
+)
+
+// WithDisallowedOps returns a copy of s where expressions using any of the
+// operators 'ops' are rejected, with an error naming the operator and its
+// position. For instance, to forbid bitwise operators:
+//
+//	s = s.WithDisallowedOps(token.SHL, token.SHR, token.AND, token.OR, token.XOR, token.AND_NOT)
+//
+// Operators are checked on the expression as written, including the
+// bodies of user-defined functions, before any other transformation. Both
+// binary and unary forms are forbidden: token.SUB forbids "a - b" and "-a".
+func (s Scope) WithDisallowedOps(ops ...token.Token) Scope {
+	disallowed := maps.Clone(s.disallowed)
+	if disallowed == nil {
+		disallowed = make(map[token.Token]bool)
+	}
+	for _, op := range ops {
+		disallowed[op] = true
+	}
+	s.disallowed = disallowed
+	return s
+}
+
+// allowed checks that the operator of 'x', if any, is not disallowed.
+func (s Scope) allowed(e *evaluation, x ast.Expr) error {
+	var (
+		op  token.Token
+		pos token.Pos
+	)
+	switch x := x.(type) {
+	case *ast.BinaryExpr:
+		op, pos = x.Op, x.OpPos
+	case *ast.UnaryExpr:
+		op, pos = x.Op, x.OpPos
+	default:
+		return nil
+	}
+	if s.disallowed[op] {
+		return e.errorf(pos, "operator %v is not allowed", op)
+	}
+	return nil
+}
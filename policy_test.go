@@ -0,0 +1,41 @@
+package calc
+
+import (
+	"go/token"
+	"testing"
+)
+
+func TestWithDisallowedOps(t *testing.T) {
+	s := Scope{}.WithDisallowedOps(token.SHL, token.XOR)
+	if err := s.DefineFunc("shift", []string{"x"}, "x << 1"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		err  string // empty if allowed.
+	}{
+		{"1 + 2*3", ""},
+		{"1 >> 1", ""},
+		{"1 << 2", "eval:1:3: operator << is not allowed"},
+		{"1 + (2 ^ 3)", "eval:1:8: operator ^ is not allowed"},
+		{"^1", "eval:1:1: operator ^ is not allowed"},
+		{"shift(1)", "shift:1:3: operator << is not allowed"},
+	}
+	for _, test := range tests {
+		_, err := s.Eval(test.expr)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("Eval(%q) unexpected error: %v", test.expr, err)
+		case test.err != "" && (err == nil || err.Error() != test.err):
+			t.Errorf("Eval(%q) error = %v, want %v", test.expr, err, test.err)
+		}
+	}
+
+	// The original scope is not changed.
+	if _, err := s.WithDisallowedOps(token.ADD).Eval("1 + 2"); err == nil {
+		t.Errorf("Eval(%q) expected an error", "1 + 2")
+	}
+	if _, err := s.Eval("1 + 2"); err != nil {
+		t.Errorf("Eval(%q) unexpected error: %v", "1 + 2", err)
+	}
+}
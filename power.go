@@ -0,0 +1,96 @@
+package calc
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// WithPowerOperator returns a copy of s where "a ** b" is "a" to the power
+// "b", like in Python: it is rewritten as "pow(a, b)" before evaluation.
+//
+// The power operator binds tighter than multiplication and unary operators,
+// and is right-associative: "2 * 3 ** 2" is 18, "-2 ** 2" is -4, and
+// "2 ** 3 ** 2" is 512.
+//
+// Go has no pointers in constant expressions, so there is no ambiguity with
+// the dereference operator: "a * *b" is "a ** b" too.
+func (s Scope) WithPowerOperator() Scope {
+	s.power = true
+	return s
+}
+
+// unpower rewrites the power operators in the chain of multiplicative
+// operations rooted in x into calls to pow.
+//
+// Go parses "a ** b" as "a * (*b)", with the precedence and associativity
+// of the multiplication: "a * b ** c" is "(a * b) * (*c)". The operands of
+// the chain are collected, and the power operators regrouped from the right.
+func unpower(x ast.Expr) ast.Expr {
+	b, ok := x.(*ast.BinaryExpr)
+	if !ok || b.Op.Precedence() != token.MUL.Precedence() {
+		return x
+	}
+	var (
+		operands []ast.Expr
+		links    []*ast.BinaryExpr
+	)
+	for cur := b; ; {
+		links = append([]*ast.BinaryExpr{cur}, links...)
+		operands = append([]ast.Expr{cur.Y}, operands...)
+		inner, ok := cur.X.(*ast.BinaryExpr)
+		if !ok || inner.Op.Precedence() != token.MUL.Precedence() {
+			operands = append([]ast.Expr{cur.X}, operands...)
+			break
+		}
+		cur = inner
+	}
+	isPower := func(i int) bool {
+		_, star := operands[i].(*ast.StarExpr)
+		return star && links[i-1].Op == token.MUL
+	}
+	exponent := func(i int) ast.Expr {
+		if i > 0 && isPower(i) {
+			return operands[i].(*ast.StarExpr).X
+		}
+		return operands[i]
+	}
+
+	var res ast.Expr
+	for i := 0; i < len(operands); {
+		// operands i to j are a tower of powers.
+		j := i
+		for j+1 < len(operands) && isPower(j+1) {
+			j++
+		}
+		t := exponent(j)
+		for k := j - 1; k >= i; k-- {
+			base := operands[k]
+			if k > i {
+				base = exponent(k)
+			}
+			t = power(base, t, links[k].OpPos)
+		}
+		if res == nil {
+			res = t
+		} else {
+			link := links[i-1]
+			res = &ast.BinaryExpr{X: res, OpPos: link.OpPos, Op: link.Op, Y: t}
+		}
+		i = j + 1
+	}
+	return res
+}
+
+// power returns "pow(base, exp)", unary operators on 'base' are applied to
+// the result instead: "-2 ** 2" is "-pow(2, 2)".
+func power(base, exp ast.Expr, pos token.Pos) ast.Expr {
+	if u, ok := base.(*ast.UnaryExpr); ok {
+		return &ast.UnaryExpr{OpPos: u.OpPos, Op: u.Op, X: power(u.X, exp, pos)}
+	}
+	return &ast.CallExpr{
+		Fun:    &ast.Ident{NamePos: base.Pos(), Name: "pow"},
+		Lparen: pos,
+		Args:   []ast.Expr{base, exp},
+		Rparen: exp.End(),
+	}
+}
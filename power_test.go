@@ -0,0 +1,57 @@
+package calc
+
+import (
+	"errors"
+	"go/constant"
+	"strconv"
+	"testing"
+)
+
+func TestPowerOperator(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"2 ** 10", "1024"},
+		{"2**10", "1024"},
+		{"2 * *10", "1024"},
+		{"2 * 3 ** 2", "18"},
+		{"3 ** 2 * 2", "18"},
+		{"2 ** 3 ** 2", "512"},
+		{"(2 ** 3) ** 2", "64"},
+		{"-2 ** 2", "-4"},
+		{"(-2) ** 2", "4"},
+		{"2 ** -1", "0.5"},
+		{"2 ** -3 ** 2", "0.001953125"},
+		{"1 + 2 ** 3 / 4", "3"},
+		{"16 / 2 ** 2", "4"},
+		{"4 ** 0.5 * 3", "6"},
+		{"1.5 ** 2", "2.25"},
+		{"2 ** (1 + 1)", "4"},
+		{"sqrt(2 ** 4)", "4"},
+		{"2 ** 256 == 1 << 256", "true"},
+	}
+	s := Scope{}.WithPowerOperator()
+	for _, test := range tests {
+		got, err := s.Eval(test.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		str := got.String()
+		if got.Kind() == constant.Float {
+			f, _ := constant.Float64Val(got)
+			str = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		if str != test.want {
+			t.Errorf("Eval(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	if _, err := s.Eval("0 ** -1"); !errors.Is(err, ErrDivByZero) {
+		t.Errorf("Eval(%q) error = %v, want ErrDivByZero", "0 ** -1", err)
+	}
+	if _, err := (Scope{}).Eval("2 ** 10"); err == nil {
+		t.Errorf("Eval(%q) without the power operator expected an error", "2 ** 10")
+	}
+}
@@ -0,0 +1,202 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Preset is a named way of formatting the result of an expression, see
+// [Scope.FormatPreset].
+type Preset struct {
+	Name string
+	// Format evaluates 'expr' in 's' and formats the result.
+	Format func(s Scope, expr string) (string, error)
+}
+
+// Builtin presets, with sample outputs:
+//
+//	PresetCurrency    1234.5 -> "1,234.50", -1234.567 -> "-1,234.57"
+//	PresetPercent     0.125 -> "12.5%", 1/3. -> "33.33%"
+//	PresetScientific  1234.5 -> "1.2345e+03", 1e400 -> "1e+400"
+//	PresetHex         255 -> "0xff", -1<<64 -> "-0x10000000000000000"
+//	PresetDuration    90*60*1e9 -> "1h30m0s" (the result is in nanoseconds)
+var (
+	// PresetCurrency rounds to cents, half to even, with thousands separators.
+	PresetCurrency = Preset{"currency", formatCurrency}
+	// PresetPercent is the value times 100, rounded to at most 2 decimals.
+	PresetPercent = Preset{"percent", formatPercent}
+	// PresetScientific is the scientific notation of [Scope.SciParts].
+	PresetScientific = Preset{"scientific", formatScientific}
+	// PresetHex is the hexadecimal notation of integers, with a 0x prefix.
+	PresetHex = Preset{"hex", formatHex}
+	// PresetDuration is the notation of time.Duration, for integer nanoseconds.
+	PresetDuration = Preset{"duration", formatDuration}
+)
+
+// FormatPreset evaluates 'expr', and formats the result with 'preset'.
+func (s Scope) FormatPreset(expr string, preset Preset) (string, error) {
+	if preset.Format == nil {
+		return "", fmt.Errorf("invalid preset %q", preset.Name)
+	}
+	return preset.Format(s, expr)
+}
+
+// WithPreset returns a copy of s whose results are formatted with
+// 'preset' by [Scope.EvalRow].
+func (s Scope) WithPreset(preset Preset) Scope {
+	s.preset = preset
+	return s
+}
+
+// EvalRow evaluates each of 'exprs', and formats the results with the
+// preset of s, see [Scope.WithPreset], as the cells of a table row, for
+// instance for a [text/tabwriter.Writer].
+//
+// Without preset, floats and complex numbers use the shortest notation
+// that represents them exactly as float64, like strconv's 'g' format with
+// precision -1, strings are unquoted, and other values use their exact
+// notation.
+//
+// The row always has one cell per expression. The cells of expressions
+// that fail are empty, and their errors are joined in the returned error,
+// with the index of their column.
+func (s Scope) EvalRow(exprs []string) ([]string, error) {
+	format := s.preset.Format
+	if format == nil {
+		format = formatDefault
+	}
+	row := make([]string, len(exprs))
+	var errs []error
+	for i, expr := range exprs {
+		str, err := format(s, expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("column %d: %w", i, err))
+			continue
+		}
+		row[i] = str
+	}
+	return row, errors.Join(errs...)
+}
+
+func formatDefault(s Scope, expr string) (string, error) {
+	v, err := s.eval(expr)
+	if err != nil {
+		return "", err
+	}
+	switch v.Kind() {
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case constant.Complex:
+		re, _ := constant.Float64Val(constant.Real(v))
+		im, _ := constant.Float64Val(constant.Imag(v))
+		return strconv.FormatComplex(complex(re, im), 'g', -1, 128), nil
+	case constant.String:
+		return constant.StringVal(v), nil
+	}
+	return v.ExactString(), nil
+}
+
+var (
+	presetsMu sync.RWMutex
+	presets   = map[string]Preset{}
+)
+
+func init() {
+	for _, p := range []Preset{PresetCurrency, PresetPercent, PresetScientific, PresetHex, PresetDuration} {
+		presets[p.Name] = p
+	}
+}
+
+// RegisterPreset makes the custom preset 'p' available by its name, with
+// [LookupPreset]. An error is returned if the name is already taken.
+func RegisterPreset(p Preset) error {
+	if p.Name == "" || p.Format == nil {
+		return fmt.Errorf("invalid preset %q", p.Name)
+	}
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	if _, ok := presets[p.Name]; ok {
+		return fmt.Errorf("preset %q already registered", p.Name)
+	}
+	presets[p.Name] = p
+	return nil
+}
+
+// LookupPreset returns the preset registered as 'name', builtin presets
+// included ("currency", "percent", ...).
+func LookupPreset(name string) (Preset, bool) {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+	p, ok := presets[name]
+	return p, ok
+}
+
+func formatCurrency(s Scope, expr string) (string, error) {
+	r, err := s.RoundTo(expr, 2)
+	if err != nil {
+		return "", err
+	}
+	str := r.FloatString(2)
+	sign := ""
+	if r.Sign() < 0 {
+		sign, str = "-", str[1:]
+	}
+	whole, cents, _ := strings.Cut(str, ".")
+	return sign + groupThousands(whole, ',') + "." + cents, nil
+}
+
+// groupThousands inserts 'sep' between groups of three digits.
+func groupThousands(digits string, sep rune) string {
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteRune(sep)
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+func formatPercent(s Scope, expr string) (string, error) {
+	r, err := s.rat(expr)
+	if err != nil {
+		return "", err
+	}
+	str := roundHalfEven(r.Mul(r, big.NewRat(100, 1)), 2).FloatString(2)
+	str = strings.TrimRight(strings.TrimRight(str, "0"), ".")
+	return str + "%", nil
+}
+
+func formatScientific(s Scope, expr string) (string, error) {
+	m, e, err := s.SciParts(expr)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(m, 'f', -1, 64) + fmt.Sprintf("e%+03d", e), nil
+}
+
+func formatHex(s Scope, expr string) (string, error) {
+	i, err := s.BigInt(expr)
+	if err != nil {
+		return "", err
+	}
+	if i.Sign() < 0 {
+		return "-0x" + i.Neg(i).Text(16), nil
+	}
+	return "0x" + i.Text(16), nil
+}
+
+func formatDuration(s Scope, expr string) (string, error) {
+	i, err := s.Int(expr)
+	if err != nil {
+		return "", err
+	}
+	return time.Duration(i).String(), nil
+}
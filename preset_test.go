@@ -0,0 +1,88 @@
+package calc
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFormatPreset(t *testing.T) {
+	tests := []struct {
+		expr   string
+		preset Preset
+		want   string
+	}{
+		{"1234.5", PresetCurrency, "1,234.50"},
+		{"-1234.567", PresetCurrency, "-1,234.57"},
+		{"1e6", PresetCurrency, "1,000,000.00"},
+		{"0.125", PresetCurrency, "0.12"},
+		{"-0.001", PresetCurrency, "0.00"},
+		{"0.125", PresetPercent, "12.5%"},
+		{"1/3.", PresetPercent, "33.33%"},
+		{"2", PresetPercent, "200%"},
+		{"1234.5", PresetScientific, "1.2345e+03"},
+		{"1e400", PresetScientific, "1e+400"},
+		{"-0.5", PresetScientific, "-5e-01"},
+		{"255", PresetHex, "0xff"},
+		{"-1<<64", PresetHex, "-0x10000000000000000"},
+		{"90*60*1e9", PresetDuration, "1h30m0s"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.FormatPreset(test.expr, test.preset)
+		if err != nil {
+			t.Errorf("FormatPreset(%q, %s) unexpected error: %v", test.expr, test.preset.Name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("FormatPreset(%q, %s) = %q, want %q", test.expr, test.preset.Name, got, test.want)
+		}
+	}
+	if _, err := (Scope{}).FormatPreset("1.5", PresetHex); err == nil {
+		t.Errorf("FormatPreset(%q, hex) expected an error", "1.5")
+	}
+}
+
+func TestRegisterPreset(t *testing.T) {
+	bits := Preset{"test-bits", func(s Scope, expr string) (string, error) {
+		n, err := s.ByteLen(expr)
+		return string(rune('0'+n)) + " bytes", err
+	}}
+	if err := RegisterPreset(bits); err != nil {
+		t.Fatalf("RegisterPreset() unexpected error: %v", err)
+	}
+	if err := RegisterPreset(bits); err == nil {
+		t.Errorf("RegisterPreset() twice expected an error")
+	}
+	p, ok := LookupPreset("test-bits")
+	if !ok {
+		t.Fatalf("LookupPreset() did not find the preset")
+	}
+	if got, err := (Scope{}).FormatPreset("1<<16", p); err != nil || got != "3 bytes" {
+		t.Errorf("FormatPreset() = %q, %v, want %q", got, err, "3 bytes")
+	}
+	if _, ok := LookupPreset("currency"); !ok {
+		t.Errorf("LookupPreset(%q) did not find the builtin preset", "currency")
+	}
+}
+
+func TestEvalRow(t *testing.T) {
+	var s Scope
+	s.Assign("price", "1234.5")
+	row, err := s.EvalRow([]string{"price", `"total"`, "1/3.", "1 << 70", "2i", "price > 0"})
+	if err != nil {
+		t.Fatalf("EvalRow() unexpected error: %v", err)
+	}
+	want := []string{"1234.5", "total", "0.3333333333333333", "1180591620717411303424", "(0+2i)", "true"}
+	if !slices.Equal(row, want) {
+		t.Errorf("EvalRow() = %q, want %q", row, want)
+	}
+
+	row, err = s.WithPreset(PresetCurrency).EvalRow([]string{"price", "x", "price*2", `"a"`})
+	want = []string{"1,234.50", "", "2,469.00", ""}
+	if !slices.Equal(row, want) {
+		t.Errorf("EvalRow() = %q, want %q", row, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "column 1: ") || !strings.Contains(err.Error(), "column 3: ") {
+		t.Errorf("EvalRow() error = %v, want errors for columns 1 and 3", err)
+	}
+}
@@ -0,0 +1,137 @@
+package calc
+
+import (
+	"go/token"
+	"math/big"
+	"strings"
+)
+
+// rewriteRadixFractions rewrites hexadecimal, octal and binary literals with
+// a fractional part, but no exponent, into valid Go syntax:
+//
+//	0xFF.8 -> 0xFF.8p0
+//	0b1.1  -> (0b11/2.0)
+//	0o7.4  -> (0o74/8.0)
+//
+// Other literals are left untouched.
+func rewriteRadixFractions(expr string) string {
+	var b strings.Builder
+	last := 0
+	for _, l := range scan(expr) {
+		if l.tok != token.FLOAT || len(l.lit) < 2 || l.lit[0] != '0' {
+			continue
+		}
+		var base int64
+		switch l.lit[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		default:
+			continue
+		}
+		prefix, digits := l.lit[:2], l.lit[2:]
+		var lit string
+		if base == 16 {
+			if strings.ContainsAny(digits, "pP") {
+				continue
+			}
+			lit = l.lit + "p0"
+		} else {
+			whole, frac, ok := strings.Cut(digits, ".")
+			if !ok || !isRadixDigits(whole+frac, base) {
+				continue
+			}
+			den := new(big.Int).Exp(big.NewInt(base), big.NewInt(int64(len(strings.ReplaceAll(frac, "_", "")))), nil)
+			lit = "(" + prefix + whole + frac + "/" + den.String() + ".0)"
+		}
+		b.WriteString(expr[last:l.off])
+		b.WriteString(lit)
+		last = l.end
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
+
+// isRadixDigits reports whether 's' is made of digits in 'base' (up to 16),
+// possibly separated by underscores.
+func isRadixDigits(s string, base int64) bool {
+	for _, r := range s {
+		d := base
+		switch {
+		case '0' <= r && r <= '9':
+			d = int64(r - '0')
+		case 'a' <= r && r <= 'f':
+			d = int64(r-'a') + 10
+		case 'A' <= r && r <= 'F':
+			d = int64(r-'A') + 10
+		}
+		if r != '_' && d >= base {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// WithInputBase returns a copy of s where integer literals without prefix
+// are in 'base', 2, 8, 10 or 16, as in a disassembler where all numbers are
+// hexadecimal: with base 16, "0ff + 10" is 0xff + 0x10, so 271.
+//
+// Only literals starting with a decimal digit are numbers: in base 16,
+// "ff" or "a" are identifiers, "0ff" is a number. Literals with a prefix
+// ("0x10", "0b1") keep their base, and so do floats ("1.5") and imaginary
+// numbers ("2i"). In base 16, 'e' is a digit, not an exponent: "1e3" is
+// 0x1e3, and "1e+3" is 0x1e + 3. Invalid digits are errors: in base 8,
+// "9" is an error.
+//
+// Other bases are ignored: s is returned unchanged.
+func (s Scope) WithInputBase(base int) Scope {
+	switch base {
+	case 2, 8, 10, 16:
+		s.inputBase = base
+	}
+	return s
+}
+
+// rewriteInputBase prefixes the integer literals of 'expr' without prefix
+// with the prefix of 'base'.
+func rewriteInputBase(expr string, base int) string {
+	prefix := map[int]string{2: "0b", 8: "0o", 16: "0x"}[base]
+	lexemes := scan(expr)
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(lexemes); i++ {
+		l := lexemes[i]
+		if l.tok != token.INT && l.tok != token.FLOAT || !isDecimal(l.lit) {
+			continue
+		}
+		// In base 16, "12ab" is scanned as the number 12 and the
+		// identifier ab.
+		lit, end := l.lit, l.end
+		if next := i + 1; base == 16 && next < len(lexemes) && lexemes[next].tok == token.IDENT && lexemes[next].off == l.end && isRadixDigits(lexemes[next].lit, 16) {
+			lit, end = lit+lexemes[next].lit, lexemes[next].end
+			i++
+		}
+		switch {
+		case isRadixDigits(lit, int64(base)) || l.tok == token.INT:
+			// Invalid digits are left to the parser to report.
+			lit = prefix + lit
+		case base == 16:
+			// An exponent with a sign: "1e+3".
+			j := strings.IndexAny(lit, "+-")
+			if j < 0 || !isRadixDigits(lit[:j], 16) || !isRadixDigits(lit[j+1:], 16) {
+				continue
+			}
+			lit = prefix + lit[:j+1] + prefix + lit[j+1:]
+		default:
+			continue
+		}
+		b.WriteString(expr[last:l.off])
+		b.WriteString(lit)
+		last = end
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package calc
+
+import "testing"
+
+func TestRadixFractions(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		// Valid Go literals.
+		{"0xFF", 255},
+		{"0xFF.8p0", 255.5},
+		{"0x1.8p1", 3},
+		{"0x.8p0", 0.5},
+		{"0b101", 5},
+		{"1.5", 1.5},
+		{"017.5", 17.5},
+		// Rewritten literals.
+		{"0xFF.8", 255.5},
+		{"0x.8", 0.5},
+		{"0xFF.", 255},
+		{"0XA.C", 10.75},
+		{"0b1.1", 1.5},
+		{"0b1_0.0_1", 2.25},
+		{"0o7.4", 7.5},
+		{"2*0xFF.8 + 0b0.1", 511.5},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Float64(test.expr)
+		if err != nil {
+			t.Errorf("Float64(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Float64(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{"0b1.2", "0o8.1", "0b1.1e3", "0x1p"} {
+		if _, err := (Scope{}).Float64(expr); err == nil {
+			t.Errorf("Float64(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestWithInputBase(t *testing.T) {
+	var c Scope
+	c.Assign("ff", "1")
+	tests := []struct {
+		base int
+		expr string
+		want string
+	}{
+		{16, "0ff + 10", "271"},
+		{16, "12ab", "4779"},
+		{16, "1e3", "483"},
+		{16, "1e+3", "33"},
+		{16, "1e-3f", "-33"},
+		{16, "ff + 1", "2"},
+		{16, "0x10 + 0b11 + 1.5", "20.5"},
+		{16, "2i", "(0 + 2i)"},
+		{8, "17", "15"},
+		{8, "0x17", "23"},
+		{2, "101 << 1", "10"},
+		{2, "1.5", "1.5"},
+		{10, "017", "15"},
+		{7, "017", "15"},
+		{0, "10", "10"},
+	}
+	for _, test := range tests {
+		got, err := c.WithInputBase(test.base).Eval(test.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) in base %d unexpected error: %v", test.expr, test.base, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("Eval(%q) in base %d = %v, want %v", test.expr, test.base, got, test.want)
+		}
+	}
+	for _, test := range []struct {
+		base int
+		expr string
+	}{{8, "9"}, {2, "12"}, {16, "12g"}} {
+		if _, err := c.WithInputBase(test.base).Eval(test.expr); err == nil {
+			t.Errorf("Eval(%q) in base %d expected an error", test.expr, test.base)
+		}
+	}
+	if got, err := c.WithInputBase(16).WithInputBase(7).Eval("10"); err != nil || got.String() != "16" {
+		t.Errorf("Eval(\"10\") after an unsupported base = %v, %v, want 16", got, err)
+	}
+}
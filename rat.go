@@ -0,0 +1,298 @@
+package calc
+
+import (
+	"fmt"
+	"go/constant"
+	"math"
+	"math/big"
+)
+
+// toRat returns the exact rational value of 'val', if it is a real number.
+func toRat(val constant.Value) (*big.Rat, bool) {
+	switch v := constant.Val(constant.ToFloat(val)).(type) {
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case *big.Int:
+		return new(big.Rat).SetInt(v), true
+	case *big.Rat:
+		return new(big.Rat).Set(v), true
+	case *big.Float:
+		r, _ := v.Rat(nil) // always exact for finite values.
+		return r, r != nil
+	}
+	return nil, false
+}
+
+// WithMaxDenominator returns a copy of s where the rationals returned by
+// [Scope.Rats], [Scope.Mixed], [Scope.RatFloat], [Scope.Exact] and
+// [Scope.SignMagnitude] are approximated by the nearest rational whose
+// denominator is at most 'd', for instance to display nice fractions: with
+// 'd' 10, "math.Pi" is 22/7.
+//
+// Results are then approximate, even when the exact value is known. Other
+// methods, like [Scope.RoundTo], are not affected. If d is not positive,
+// rationals are exact.
+func (s Scope) WithMaxDenominator(d int64) Scope {
+	s.maxDenom = max(d, 0)
+	return s
+}
+
+// limit approximates 'r' in place, if s has a max denominator.
+func (s Scope) limit(r *big.Rat) *big.Rat {
+	if s.maxDenom == 0 {
+		return r
+	}
+	return r.Set(limitDenominator(r, big.NewInt(s.maxDenom)))
+}
+
+// limitDenominator returns the best rational approximation of 'r' whose
+// denominator is at most 'maxDenom', using the convergents and semiconvergents
+// of its continued fraction.
+func limitDenominator(r *big.Rat, maxDenom *big.Int) *big.Rat {
+	if r.Denom().Cmp(maxDenom) <= 0 {
+		return new(big.Rat).Set(r)
+	}
+	// Convergents p0/q0 and p1/q1 of |r|.
+	p0, q0, p1, q1 := big.NewInt(0), big.NewInt(1), big.NewInt(1), big.NewInt(0)
+	n, d := new(big.Int).Abs(r.Num()), new(big.Int).Set(r.Denom())
+	for {
+		a, m := new(big.Int).QuoRem(n, d, new(big.Int))
+		q2 := new(big.Int).Add(q0, new(big.Int).Mul(a, q1))
+		if q2.Cmp(maxDenom) > 0 {
+			break
+		}
+		p0, q0, p1, q1 = p1, q1, new(big.Int).Add(p0, new(big.Int).Mul(a, p1)), q2
+		n, d = d, m
+	}
+	// The best semiconvergent, and the last convergent: the closest wins.
+	k := new(big.Int).Quo(new(big.Int).Sub(maxDenom, q0), q1)
+	semi := new(big.Rat).SetFrac(
+		new(big.Int).Add(p0, new(big.Int).Mul(k, p1)),
+		new(big.Int).Add(q0, new(big.Int).Mul(k, q1)),
+	)
+	conv := new(big.Rat).SetFrac(p1, q1)
+	abs := new(big.Rat).Abs(r)
+	dist := func(x *big.Rat) *big.Rat {
+		d := new(big.Rat).Sub(x, abs)
+		return d.Abs(d)
+	}
+	best := conv
+	if dist(semi).Cmp(dist(conv)) < 0 {
+		best = semi
+	}
+	if r.Sign() < 0 {
+		best.Neg(best)
+	}
+	return best
+}
+
+// rat evaluates 'expr' as an exact rational.
+func (s Scope) rat(expr string) (*big.Rat, error) {
+	val, err := s.eval(expr)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := toRat(val)
+	if !ok {
+		return nil, fmt.Errorf("not representable as a rational (%v): %q", val.Kind(), expr)
+	}
+	return r, nil
+}
+
+// RoundTo evaluates 'expr' exactly, and rounds it to 'places' decimal
+// places, using the round-half-to-even rule (banker's rounding): 2.5 is
+// rounded to 2, and 3.5 to 4.
+//
+// Negative 'places' round to tens, hundreds, etc.
+//
+// No floating point is involved, the result is the exact rounded rational.
+func (s Scope) RoundTo(expr string, places int) (*big.Rat, error) {
+	r, err := s.rat(expr)
+	if err != nil {
+		return nil, err
+	}
+	return roundHalfEven(r, places), nil
+}
+
+// SigFigs evaluates 'expr' exactly, rounds it to 'n' significant figures
+// like [Scope.RoundTo] does (half to even), and returns the nearest float64
+// of the rounded value: SigFigs("123.456", 2) is 120, and
+// SigFigs("0.0012345", 3) is 0.00123.
+//
+// An error is returned if 'n' is not positive.
+func (s Scope) SigFigs(expr string, n int) (float64, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid number of significant figures %d: %q", n, expr)
+	}
+	r, err := s.rat(expr)
+	if err != nil {
+		return 0, err
+	}
+	if r.Sign() == 0 {
+		return 0, nil
+	}
+	// e is the exponent of the leading digit: 10**e <= |r| < 10**(e+1).
+	abs := new(big.Rat).Abs(r)
+	e := len(abs.Num().String()) - len(abs.Denom().String())
+	for abs.Cmp(ratPow10(e)) < 0 {
+		e--
+	}
+	for abs.Cmp(ratPow10(e+1)) >= 0 {
+		e++
+	}
+	f, _ := roundHalfEven(r, n-1-e).Float64()
+	return f, nil
+}
+
+// ratPow10 returns 10**n, for any n.
+func ratPow10(n int) *big.Rat {
+	if n < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), pow10(-n))
+	}
+	return new(big.Rat).SetInt(pow10(n))
+}
+
+// FixedPoint evaluates 'expr' exactly, and returns it as a fixed-point
+// integer with 'scale' decimal places: the value multiplied by 10**scale,
+// rounded half to even like [Scope.RoundTo]. FixedPoint("1.2345", 2) is
+// 123.
+//
+// An error is returned if the scaled value overflows int64.
+func (s Scope) FixedPoint(expr string, scale int) (int64, error) {
+	r, err := s.RoundTo(expr, scale)
+	if err != nil {
+		return 0, err
+	}
+	if scale >= 0 {
+		r.Mul(r, new(big.Rat).SetInt(pow10(scale)))
+	} else {
+		r.Quo(r, new(big.Rat).SetInt(pow10(-scale)))
+	}
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("fixed-point value overflows int64: %q", expr)
+	}
+	return r.Num().Int64(), nil
+}
+
+// SignMagnitude evaluates 'expr' exactly, and returns its sign, -1, 0 or
+// 1, and its absolute value, for instance to render them separately.
+//
+// Complex results are an error.
+func (s Scope) SignMagnitude(expr string) (sign int, magnitude *big.Rat, err error) {
+	r, err := s.rat(expr)
+	if err != nil {
+		return 0, nil, err
+	}
+	return r.Sign(), s.limit(r.Abs(r)), nil
+}
+
+// roundHalfEven rounds 'r' to 'places' decimal places, ties to even.
+func roundHalfEven(r *big.Rat, places int) *big.Rat {
+	scale := new(big.Rat).SetInt(pow10(places))
+	if places < 0 {
+		scale.Inv(new(big.Rat).SetInt(pow10(-places)))
+	}
+	x := new(big.Rat).Mul(r, scale)
+
+	// Round the magnitude, the rule is symmetric.
+	num := new(big.Int).Abs(x.Num())
+	q, rem := new(big.Int).QuoRem(num, x.Denom(), new(big.Int))
+	switch new(big.Int).Lsh(rem, 1).Cmp(x.Denom()) {
+	case 1:
+		q.Add(q, big.NewInt(1))
+	case 0:
+		if q.Bit(0) == 1 {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if x.Sign() < 0 {
+		q.Neg(q)
+	}
+	return x.SetInt(q).Quo(x, scale)
+}
+
+// pow10 returns 10**n, for n >= 0.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// RatFloat evaluates 'expr' exactly, and returns both its exact rational
+// value and the nearest float64.
+//
+// The float64 is ±Inf if the value is beyond the range of float64. Complex
+// results are an error.
+func (s Scope) RatFloat(expr string) (*big.Rat, float64, error) {
+	r, err := s.rat(expr)
+	if err != nil {
+		return nil, 0, err
+	}
+	r = s.limit(r)
+	f, _ := r.Float64()
+	return r, f, nil
+}
+
+// ContinuedFraction evaluates 'expr' exactly, and returns the terms of its
+// continued fraction expansion [a0; a1, a2, ...], at most 'maxTerms' of
+// them: "415/93." is [4, 2, 6, 7], because 415/93 = 4 + 1/(2 + 1/(6 + 1/7)).
+// Note that, like in Go, "415/93" is the integer division, and is [4].
+//
+// The expansion is the regular one, where a0 is the floor of the value, so
+// it is negative for negative values, and the other terms are positive. An
+// error is returned if a term overflows int64.
+func (s Scope) ContinuedFraction(expr string, maxTerms int) ([]int64, error) {
+	r, err := s.rat(expr)
+	if err != nil {
+		return nil, err
+	}
+	num, den := new(big.Int).Set(r.Num()), new(big.Int).Set(r.Denom())
+	var terms []int64
+	for len(terms) < maxTerms && den.Sign() != 0 {
+		// Euclidean division: the remainder is non-negative.
+		q, m := new(big.Int).DivMod(num, den, new(big.Int))
+		if !q.IsInt64() {
+			return nil, fmt.Errorf("continued fraction term overflows int64: %q", expr)
+		}
+		terms = append(terms, q.Int64())
+		num, den = den, m
+	}
+	return terms, nil
+}
+
+// Exact evaluates 'expr' and returns its value in the Go type that
+// represents it most faithfully:
+//
+//	*big.Int    for integers, including integral floats like "4/2."
+//	*big.Rat    for other real numbers, like "1/3." or "0.1"
+//	complex128  for complex numbers with a non zero imaginary part
+//	bool        for booleans
+//	string      for strings
+//
+// Integers and rationals are exact, complex numbers are rounded to the
+// nearest complex128, and an error is returned if they overflow it.
+func (s Scope) Exact(expr string) (any, error) {
+	val, err := s.eval(expr)
+	if err != nil {
+		return nil, err
+	}
+	switch val.Kind() {
+	case constant.Bool:
+		return constant.BoolVal(val), nil
+	case constant.String:
+		return constant.StringVal(val), nil
+	}
+	if r, ok := toRat(val); ok {
+		if r = s.limit(r); r.IsInt() {
+			return new(big.Int).Set(r.Num()), nil
+		}
+		return r, nil
+	}
+	if val.Kind() != constant.Complex {
+		return nil, fmt.Errorf("not representable exactly (%v): %q", val.Kind(), expr)
+	}
+	re, _ := constant.Float64Val(constant.Real(val))
+	im, _ := constant.Float64Val(constant.Imag(val))
+	if math.IsInf(re, 0) || math.IsInf(im, 0) {
+		return nil, fmt.Errorf("complex overflows complex128: %q", expr)
+	}
+	return complex(re, im), nil
+}
@@ -0,0 +1,362 @@
+package calc
+
+import (
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRoundTo(t *testing.T) {
+	tests := []struct {
+		expr   string
+		places int
+		want   string
+	}{
+		{"2.5", 0, "2"},
+		{"3.5", 0, "4"},
+		{"-2.5", 0, "-2"},
+		{"-3.5", 0, "-4"},
+		{"2.51", 0, "3"},
+		{"2.49", 0, "2"},
+		{"0.125", 2, "0.12"},
+		{"0.135", 2, "0.14"},
+		{"1/3.", 4, "0.3333"},
+		{"2/3.", 4, "0.6667"},
+		{"0.1 + 0.2", 1, "0.3"},
+		{"1250", -2, "1200"},
+		{"1350", -2, "1400"},
+		{"7", 2, "7"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.RoundTo(test.expr, test.places)
+		if err != nil {
+			t.Errorf("RoundTo(%q, %d) unexpected error: %v", test.expr, test.places, err)
+			continue
+		}
+		if want, _ := new(big.Rat).SetString(test.want); got.Cmp(want) != 0 {
+			t.Errorf("RoundTo(%q, %d) = %s, want %s", test.expr, test.places, got.RatString(), test.want)
+		}
+	}
+
+	if _, err := (Scope{}).RoundTo("1+2i", 0); err == nil {
+		t.Errorf("RoundTo(%q) expected an error", "1+2i")
+	}
+}
+
+func TestMixed(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"1 1/2", "3/2"},
+		{"1 1/2 + 1/2", "2"},
+		{"-2 3/4", "-11/4"},
+		{"1/2", "1/2"},
+		{"2 * 1 1/2", "3"},
+		{"(1 1/3) * 3", "4"},
+		{"1/2/2", "1/4"},
+		{"3", "3"},
+		{"1.5 + 1/4", "7/4"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Mixed(test.expr)
+		if err != nil {
+			t.Errorf("Mixed(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got.RatString() != test.want {
+			t.Errorf("Mixed(%q) = %v, want %v", test.expr, got.RatString(), test.want)
+		}
+	}
+	for _, expr := range []string{"1 1", "1 1/0", `"a"`, "1/2 3/4", "1 1/2 3/4"} {
+		if _, err := (Scope{}).Mixed(expr); err == nil || strings.Contains(err.Error(), "internal error") {
+			t.Errorf("Mixed(%q) error = %v, want a syntax error", expr, err)
+		}
+	}
+}
+
+func TestRats(t *testing.T) {
+	got, err := Scope{}.Rats(`1/3., 0.1, hypot(3, 4), len("a,b")`)
+	if err != nil {
+		t.Fatalf("Rats() unexpected error: %v", err)
+	}
+	want := []string{"1/3", "1/10", "5", "3"}
+	if len(got) != len(want) {
+		t.Fatalf("Rats() = %v, want %v", got, want)
+	}
+	for i, r := range got {
+		if r.RatString() != want[i] {
+			t.Errorf("Rats()[%d] = %v, want %v", i, r.RatString(), want[i])
+		}
+	}
+
+	if got, err := (Scope{}).Rats(" "); err != nil || len(got) != 0 {
+		t.Errorf("Rats(%q) = %v, %v, want an empty list", " ", got, err)
+	}
+	_, err = Scope{}.Rats("1, 2, 1i, 3")
+	if err == nil || !strings.HasPrefix(err.Error(), "element 2: ") {
+		t.Errorf("Rats() error = %v, want an element 2 error", err)
+	}
+	if _, err := (Scope{}).Rats("1,,2"); err == nil {
+		t.Errorf("Rats(%q) expected an error", "1,,2")
+	}
+}
+
+func TestContinuedFraction(t *testing.T) {
+	tests := []struct {
+		expr     string
+		maxTerms int
+		want     []int64
+	}{
+		{"415/93.", 10, []int64{4, 2, 6, 7}},
+		{"415/93.", 2, []int64{4, 2}},
+		{"3", 10, []int64{3}},
+		{"0", 10, []int64{0}},
+		{"0.5", 10, []int64{0, 2}},
+		{"-415/93.", 10, []int64{-5, 1, 1, 6, 7}},
+		{"math.Pi", 5, []int64{3, 7, 15, 1, 292}},
+		{"1", 0, nil},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.WithMathNamespace().ContinuedFraction(test.expr, test.maxTerms)
+		if err != nil {
+			t.Errorf("ContinuedFraction(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("ContinuedFraction(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"1i", `"a"`, "1 << 100"} {
+		if _, err := (Scope{}).ContinuedFraction(expr, 10); err == nil {
+			t.Errorf("ContinuedFraction(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		start, end, step string
+		want             []int64
+	}{
+		{"1", "10", "2", []int64{1, 3, 5, 7, 9}},
+		{"1", "9", "2", []int64{1, 3, 5, 7, 9}},
+		{"10", "1", "-3", []int64{10, 7, 4, 1}},
+		{"0", "0", "1", []int64{0}},
+		{"5", "1", "1", nil},
+		{"1", "5", "-1", nil},
+		{"-1<<63", "1<<63 - 1", "1<<62", []int64{-1 << 63, -1 << 62, 0, 1 << 62}},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Range(test.start, test.end, test.step)
+		if err != nil {
+			t.Errorf("Range(%q, %q, %q) unexpected error: %v", test.start, test.end, test.step, err)
+			continue
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Range(%q, %q, %q) = %v, want %v", test.start, test.end, test.step, got, test.want)
+		}
+	}
+	if _, err := (Scope{}).Range("1", "10", "0"); err == nil {
+		t.Errorf("Range() with a zero step expected an error")
+	}
+	if _, err := (Scope{}).Range("1", "10", "0.5"); err == nil {
+		t.Errorf("Range() with a fractional step expected an error")
+	}
+	for _, test := range [][3]string{
+		{"0", "1<<62", "1"},
+		{"0", "1<<40", "1"},
+		{"-1<<63", "1<<63 - 1", "1"},
+		{"1<<63 - 1", "-1<<63", "-1"},
+		{"0", "1<<24", "1"},
+	} {
+		if _, err := (Scope{}).Range(test[0], test[1], test[2]); err == nil {
+			t.Errorf("Range(%q, %q, %q) expected an error", test[0], test[1], test[2])
+		}
+	}
+	if got, err := (Scope{}).Range("1", "1<<24", "1"); err != nil || len(got) != MaxRangeLen {
+		t.Errorf("Range(\"1\", \"1<<24\", \"1\") has %d elements, %v, want %d", len(got), err, MaxRangeLen)
+	}
+}
+
+func TestSigFigs(t *testing.T) {
+	tests := []struct {
+		expr string
+		n    int
+		want float64
+	}{
+		{"123.456", 2, 120},
+		{"123.456", 4, 123.5},
+		{"123.456", 10, 123.456},
+		{"0.0012345", 3, 0.00123},
+		{"-98765", 1, -100000},
+		{"9.96", 2, 10},
+		{"1000", 1, 1000},
+		{"0.999", 1, 1},
+		{"125", 2, 120},
+		{"135", 2, 140},
+		{"0", 3, 0},
+		{"1/3.", 3, 0.333},
+		{"1e-300/3", 2, 3.3e-301},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.SigFigs(test.expr, test.n)
+		if err != nil {
+			t.Errorf("SigFigs(%q, %d) unexpected error: %v", test.expr, test.n, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("SigFigs(%q, %d) = %v, want %v", test.expr, test.n, got, test.want)
+		}
+	}
+	for _, n := range []int{0, -1} {
+		if _, err := (Scope{}).SigFigs("1", n); err == nil {
+			t.Errorf("SigFigs(\"1\", %d) expected an error", n)
+		}
+	}
+	if _, err := (Scope{}).SigFigs("1i", 2); err == nil {
+		t.Errorf("SigFigs(\"1i\", 2) expected an error")
+	}
+}
+
+func TestFixedPoint(t *testing.T) {
+	tests := []struct {
+		expr  string
+		scale int
+		want  int64
+	}{
+		{"1.2345", 2, 123},
+		{"1.235", 2, 124},
+		{"1.225", 2, 122},
+		{"-1.2345", 3, -1234},
+		{"1/3.", 4, 3333},
+		{"42", 0, 42},
+		{"1250", -2, 12},
+		{"1350", -2, 14},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.FixedPoint(test.expr, test.scale)
+		if err != nil {
+			t.Errorf("FixedPoint(%q, %d) unexpected error: %v", test.expr, test.scale, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("FixedPoint(%q, %d) = %v, want %v", test.expr, test.scale, got, test.want)
+		}
+	}
+	for _, expr := range []string{"1e18", "1i", `"1"`} {
+		if _, err := (Scope{}).FixedPoint(expr, 2); err == nil {
+			t.Errorf("FixedPoint(%q, 2) expected an error", expr)
+		}
+	}
+}
+
+func TestSignMagnitude(t *testing.T) {
+	tests := []struct {
+		expr string
+		sign int
+		mag  string
+	}{
+		{"-7/2.", -1, "7/2"},
+		{"0", 0, "0"},
+		{"1/3.", 1, "1/3"},
+		{"-1 << 70", -1, "1180591620717411303424"},
+	}
+	for _, test := range tests {
+		sign, mag, err := Scope{}.SignMagnitude(test.expr)
+		if err != nil {
+			t.Errorf("SignMagnitude(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if sign != test.sign || mag.RatString() != test.mag {
+			t.Errorf("SignMagnitude(%q) = %d, %v, want %d, %v", test.expr, sign, mag.RatString(), test.sign, test.mag)
+		}
+	}
+	if _, _, err := (Scope{}).SignMagnitude("1 + 2i"); err == nil {
+		t.Errorf("SignMagnitude(\"1 + 2i\") expected an error")
+	}
+}
+
+func TestWithMaxDenominator(t *testing.T) {
+	tests := []struct {
+		expr string
+		d    int64
+		want string
+	}{
+		{"math.Pi", 10, "22/7"},
+		{"math.Pi", 1000, "355/113"},
+		{"-math.Pi", 10, "-22/7"},
+		{"0.1", 100, "1/10"},
+		{"1/3.", 2, "1/2"},
+		{"2.4", 1, "2"},
+		{"1e-9", 100, "0"},
+		{"0.1", 0, "1/10"},
+		{"1/3.", -1, "1/3"},
+	}
+	for _, test := range tests {
+		s := Scope{}.WithMathNamespace().WithMaxDenominator(test.d)
+		rats, err := s.Rats(test.expr)
+		if err != nil {
+			t.Errorf("Rats(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got := rats[0].RatString(); got != test.want {
+			t.Errorf("Rats(%q) with max denominator %d = %v, want %v", test.expr, test.d, got, test.want)
+		}
+	}
+	s := Scope{}.WithMaxDenominator(7)
+	if got, err := s.Exact("22/7. + 1e-12"); err != nil || got.(*big.Rat).RatString() != "22/7" {
+		t.Errorf("Exact() with max denominator = %v, %v, want 22/7", got, err)
+	}
+	if got, err := s.RoundTo("1/3.", 2); err != nil || got.RatString() != "33/100" {
+		t.Errorf("RoundTo() with max denominator = %v, %v, want 33/100", got, err)
+	}
+}
+
+func TestExact(t *testing.T) {
+	huge, _ := new(big.Int).SetString("1"+strings.Repeat("0", 400), 10)
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{"42", big.NewInt(42)},
+		{"-1 << 100", new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 100))},
+		{"4/2.", big.NewInt(2)},
+		{"1e400", huge},
+		{"1/3.", big.NewRat(1, 3)},
+		{"0.1", big.NewRat(1, 10)},
+		{"-2.5", big.NewRat(-5, 2)},
+		{"1 + 2i", complex(1, 2)},
+		{"2i * 2i", big.NewInt(-4)},
+		{"1.5 + 0i", big.NewRat(3, 2)},
+		{"1 < 2", true},
+		{`"a" + "b"`, "ab"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Exact(test.expr)
+		if err != nil {
+			t.Errorf("Exact(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		var equal bool
+		switch want := test.want.(type) {
+		case *big.Int:
+			g, ok := got.(*big.Int)
+			equal = ok && g.Cmp(want) == 0
+		case *big.Rat:
+			g, ok := got.(*big.Rat)
+			equal = ok && g.Cmp(want) == 0
+		default:
+			equal = got == want
+		}
+		if !equal {
+			t.Errorf("Exact(%q) = %v (%T), want %v (%T)", test.expr, got, got, test.want, test.want)
+		}
+	}
+	for _, expr := range []string{"1e400i", "x", "1 +"} {
+		if _, err := (Scope{}).Exact(expr); err == nil {
+			t.Errorf("Exact(%q) expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package calc
+
+import (
+	"fmt"
+	"go/constant"
+	"math"
+	"math/cmplx"
+	"reflect"
+)
+
+// EvalInto evaluates 'expr' and stores the result in the value pointed to by
+// 'target'.
+//
+// 'target' must be a non-nil pointer to a value whose kind is a bool, a
+// string, or any of Go's numeric kinds (named types like time.Duration are
+// fine). An error is returned if the result is not exactly representable in
+// the target type.
+func (s Scope) EvalInto(expr string, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	e := v.Elem()
+	switch e.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := s.Int(expr)
+		if err != nil {
+			return err
+		}
+		if e.OverflowInt(i) {
+			return fmt.Errorf("not representable as %v: %q", e.Type(), expr)
+		}
+		e.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := s.Uint(expr)
+		if err != nil {
+			return err
+		}
+		if e.OverflowUint(u) {
+			return fmt.Errorf("not representable as %v: %q", e.Type(), expr)
+		}
+		e.SetUint(u)
+	case reflect.Float32:
+		f, err := s.Float32(expr)
+		if err != nil {
+			return err
+		}
+		if math.IsInf(float64(f), 0) {
+			return fmt.Errorf("not representable as %v: %q", e.Type(), expr)
+		}
+		e.SetFloat(float64(f))
+	case reflect.Float64:
+		f, err := s.Float64(expr)
+		if err != nil {
+			return err
+		}
+		if math.IsInf(f, 0) {
+			return fmt.Errorf("not representable as %v: %q", e.Type(), expr)
+		}
+		e.SetFloat(f)
+	case reflect.Complex64:
+		c, err := s.Complex64(expr)
+		if err != nil {
+			return err
+		}
+		if cmplx.IsInf(complex128(c)) {
+			return fmt.Errorf("not representable as %v: %q", e.Type(), expr)
+		}
+		e.SetComplex(complex128(c))
+	case reflect.Complex128:
+		c, err := s.Complex128(expr)
+		if err != nil {
+			return err
+		}
+		if cmplx.IsInf(c) {
+			return fmt.Errorf("not representable as %v: %q", e.Type(), expr)
+		}
+		e.SetComplex(c)
+	case reflect.Bool:
+		b, err := s.Bool(expr)
+		if err != nil {
+			return err
+		}
+		e.SetBool(b)
+	case reflect.String:
+		str, err := s.String(expr)
+		if err != nil {
+			return err
+		}
+		e.SetString(str)
+	default:
+		return fmt.Errorf("unsupported target type %v", e.Type())
+	}
+	return nil
+}
+
+// EvalStruct evaluates 'expr' where the exported fields of the struct 'v'
+// are available as variables, named after the field.
+//
+// 'v' can be a struct or a pointer to a struct. Fields are assigned like
+// [Scope.AssignValue] does, based on their kind, so named types like
+// time.Duration are fine. Fields that are unexported, embedded, or of a
+// kind other than bool, string or numeric are skipped.
+//
+// Fields take precedence over the variables of s with the same name. s
+// itself is not changed.
+func (s Scope) EvalStruct(expr string, v any) (constant.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("not a struct: %T", v)
+	}
+	c := s.clone()
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		f := rv.Field(i)
+		var x any
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			x = f.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			x = f.Uint()
+		case reflect.Float32, reflect.Float64:
+			x = f.Float()
+		case reflect.Complex64, reflect.Complex128:
+			x = f.Complex()
+		case reflect.Bool:
+			x = f.Bool()
+		case reflect.String:
+			x = f.String()
+		default:
+			continue
+		}
+		c.Delete(field.Name)
+		c.AssignValue(field.Name, x)
+	}
+	return c.eval(expr)
+}
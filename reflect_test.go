@@ -0,0 +1,36 @@
+package calc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvalInto(t *testing.T) {
+	var (
+		f32 float32
+		f64 float64
+		c64 complex64
+		c   complex128
+	)
+	if err := (Scope{}).EvalInto("1e38", &f32); err != nil || f32 != 1e38 {
+		t.Errorf("EvalInto(\"1e38\", float32) = %v, %v, want 1e38", f32, err)
+	}
+	if err := (Scope{}).EvalInto("1e39i", &c); err != nil || c != 1e39i {
+		t.Errorf("EvalInto(\"1e39i\", complex128) = %v, %v, want 1e39i", c, err)
+	}
+	for _, test := range []struct {
+		expr   string
+		target any
+	}{
+		{"1e39", &f32},
+		{"-1e39", &f32},
+		{"1e400", &f64},
+		{"1e39i", &c64},
+		{"1e39 + 1i", &c64},
+		{"1e400i", &c},
+	} {
+		if err := (Scope{}).EvalInto(test.expr, test.target); err == nil || !strings.Contains(err.Error(), "not representable") {
+			t.Errorf("EvalInto(%q, %T) error = %v, want not representable", test.expr, test.target, err)
+		}
+	}
+}
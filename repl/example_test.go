@@ -0,0 +1,37 @@
+package repl_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/etnz/calc"
+	"github.com/etnz/calc/repl"
+)
+
+func ExampleRun() {
+	var s calc.Scope
+	in := strings.NewReader(`
+h = 3600
+d = 24*h
+2.5*d
+ans/h
+:vars
+d = d/h
+:del h
+:vars
+1/0
+:clear
+:vars
+`)
+	repl.Run(in, os.Stdout, &s)
+
+	// Output:
+	// 216000
+	// 60
+	// ans = 60
+	// d = 86400
+	// h = 3600
+	// ans = 60
+	// d = 24
+	// error: eval:1:3: invalid operation: division by zero
+}
@@ -0,0 +1,116 @@
+// Package repl provides an interactive calculator shell on top of a
+// [calc.Scope].
+//
+// Each line read is either:
+//
+//	name = expr   assign the value of 'expr' to the variable 'name'
+//	expr          evaluate 'expr' and print its value, also stored in 'ans'
+//	:vars         print all variables and their values
+//	:del name     delete the variable 'name'
+//	:clear        delete all variables
+//
+// Errors are printed, and do not stop the shell.
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/etnz/calc"
+)
+
+// Run reads lines from 'in' until EOF, and executes them in 's'.
+//
+// Results and errors are printed to 'out'. The returned error is only about
+// reading 'in' or writing to 'out'.
+func Run(in io.Reader, out io.Writer, s *calc.Scope) error {
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if err := exec(out, s, line); err != nil {
+			if _, err := fmt.Fprintln(out, "error:", err); err != nil {
+				return err
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// exec executes a single line.
+func exec(out io.Writer, s *calc.Scope, line string) error {
+	if cmd, ok := strings.CutPrefix(line, ":"); ok {
+		return command(out, s, strings.Fields(cmd))
+	}
+	if name, expr, ok := assignment(line); ok {
+		return s.Set(name, expr)
+	}
+	v, err := s.EvalHistory(line)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, format(v))
+	return err
+}
+
+// command executes the command 'args[0]'.
+func command(out io.Writer, s *calc.Scope, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command")
+	}
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "vars":
+		for _, name := range s.Names() {
+			v, _ := s.Get(name)
+			if _, err := fmt.Fprintf(out, "%s = %s\n", name, format(v)); err != nil {
+				return err
+			}
+		}
+	case "del":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :del name")
+		}
+		if _, ok := s.Get(args[0]); !ok {
+			return fmt.Errorf("undefined: %s", args[0])
+		}
+		s.Delete(args[0])
+	case "clear":
+		for _, name := range s.Names() {
+			s.Delete(name)
+		}
+	default:
+		return fmt.Errorf("unknown command :%s", cmd)
+	}
+	return nil
+}
+
+// assignment splits a "name = expr" line.
+func assignment(line string) (name, expr string, ok bool) {
+	name, expr, ok = strings.Cut(line, "=")
+	name = strings.TrimSpace(name)
+	if !ok || !token.IsIdentifier(name) || strings.HasPrefix(expr, "=") {
+		return "", "", false
+	}
+	return name, expr, true
+}
+
+// format returns a human-readable form of 'v'.
+func format(v constant.Value) string {
+	switch v.Kind() {
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case constant.Complex:
+		r, _ := constant.Float64Val(constant.Real(v))
+		i, _ := constant.Float64Val(constant.Imag(v))
+		return strconv.FormatComplex(complex(r, i), 'g', -1, 128)
+	}
+	return v.String()
+}
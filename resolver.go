@@ -0,0 +1,48 @@
+package calc
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// WithResolver returns a copy of s where identifiers that are not defined
+// are resolved by calling 'fn', for instance to fetch values from a
+// database on demand. 'fn' returns the value of the variable 'name', in
+// any of the types supported by [Scope.AssignValue], and whether it was
+// found. Identifiers not found are reported undefined, as usual.
+//
+// 'fn' is only called for names that are not variables, functions or
+// imports of s, nor Go's predeclared identifiers. Within one evaluation,
+// 'fn' is called at most once per name, and its result is used for all the
+// occurrences of the name. But results are not cached across evaluations,
+// and s itself is not changed: each evaluation calls 'fn' again.
+func (s Scope) WithResolver(fn func(name string) (any, bool)) Scope {
+	s.resolver = fn
+	return s
+}
+
+// resolve defines the value of the identifier 'x', if it is undefined and
+// resolved by s.resolver.
+func (s Scope) resolve(e *evaluation, x ast.Expr) error {
+	id, ok := x.(*ast.Ident)
+	if !ok || s.resolver == nil || e.resolved[id.Name] {
+		return nil
+	}
+	if e.pkg != nil && e.pkg.Scope().Lookup(id.Name) != nil || s.known(id.Name) || s.isIndexed(id) {
+		return nil
+	}
+	if e.resolved == nil {
+		e.resolved = make(map[string]bool)
+	}
+	e.resolved[id.Name] = true // even if not found, not to ask twice.
+	v, ok := s.resolver(id.Name)
+	if !ok {
+		return nil
+	}
+	val, t, ok := constantOf(v)
+	if !ok {
+		return e.errorf(id.Pos(), "unsupported type %T for %s", v, id.Name)
+	}
+	e.bind(id, types.TypeAndValue{Type: untypedOf(t), Value: val})
+	return nil
+}
@@ -0,0 +1,46 @@
+package calc
+
+import (
+	"testing"
+)
+
+func TestWithResolver(t *testing.T) {
+	db := map[string]any{"price": 2.5, "qty": int64(4), "name": "widget", "bad": struct{}{}}
+	calls := make(map[string]int)
+	var s Scope
+	s.AssignValue("qty", 10) // variables of the scope win.
+	if err := s.DefineFunc("total", []string{"n"}, "n * price"); err != nil {
+		t.Fatal(err)
+	}
+	s = s.WithResolver(func(name string) (any, bool) {
+		calls[name]++
+		v, ok := db[name]
+		return v, ok
+	})
+
+	got, err := s.Float64("price * qty + price + total(1)")
+	if err != nil {
+		t.Fatalf("Float64() unexpected error: %v", err)
+	}
+	if got != 30 {
+		t.Errorf("Float64() = %v, want 30", got)
+	}
+	if calls["price"] != 1 || calls["qty"] != 0 || calls["total"] != 0 || calls["n"] != 0 {
+		t.Errorf("resolver calls = %v, want price once", calls)
+	}
+	if str, err := s.String(`name + "s"`); err != nil || str != "widgets" {
+		t.Errorf(`String("name + \"s\"") = %q, %v, want "widgets"`, str, err)
+	}
+	if _, err := s.Eval("price + missing + missing"); err == nil {
+		t.Errorf("Eval() with an unresolved name expected an error")
+	}
+	if calls["missing"] != 1 {
+		t.Errorf("resolver called %d times for missing, want 1", calls["missing"])
+	}
+	if _, err := s.Eval("bad"); err == nil {
+		t.Errorf("Eval() with an unsupported value expected an error")
+	}
+	if _, ok := s.Get("price"); ok {
+		t.Errorf("resolved price was stored in the scope")
+	}
+}
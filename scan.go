@@ -0,0 +1,41 @@
+package calc
+
+import (
+	"go/scanner"
+	"go/token"
+)
+
+// lexeme is a token read from an expression, with its byte offsets.
+type lexeme struct {
+	tok      token.Token
+	lit      string
+	off, end int
+}
+
+// scan splits expr into lexemes.
+//
+// Malformed input is not reported: source rewrites are best effort, and the
+// parser reports errors on the rewritten expression anyway.
+func scan(expr string) []lexeme {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(expr))
+	var sc scanner.Scanner
+	sc.Init(file, []byte(expr), nil, 0)
+
+	var lexemes []lexeme
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			return lexemes
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // automatically inserted.
+		}
+		off := file.Offset(pos)
+		text := lit
+		if text == "" {
+			text = tok.String()
+		}
+		lexemes = append(lexemes, lexeme{tok: tok, lit: lit, off: off, end: off + len(text)})
+	}
+}
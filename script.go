@@ -0,0 +1,194 @@
+package calc
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// recording is the list of script lines recorded by a Scope.
+type recording struct {
+	lines []string
+}
+
+// WithRecording returns a copy of s that records the calls to
+// [Scope.Assign], [Scope.Set] and [Scope.Import] changing it, so that they
+// can be replayed with [Scope.Load]: see [Scope.Script].
+//
+// Copies of the returned Scope share the same recording, like they share
+// the same variables.
+func (s Scope) WithRecording() Scope {
+	s.rec = &recording{}
+	return s
+}
+
+// record adds a line to the recording, if any.
+func (s *Scope) record(format string, args ...any) {
+	if s.rec != nil {
+		s.rec.lines = append(s.rec.lines, fmt.Sprintf(format, args...))
+	}
+}
+
+// Script returns the script of the changes recorded since
+// [Scope.WithRecording], in order, one per line:
+//
+//	import time
+//	h = 3600*time.S
+//	d = 24*h
+//
+// Calls to Assign that did not change the scope, because the variable
+// already existed, are not recorded. Neither are other changes, like
+// [Scope.AssignValue] or [Scope.Delete].
+//
+// The script is valid input for [Scope.Load], which rebuilds the same
+// scope, given the same imported scopes.
+func (s Scope) Script() string {
+	if s.rec == nil || len(s.rec.lines) == 0 {
+		return ""
+	}
+	return strings.Join(s.rec.lines, "\n") + "\n"
+}
+
+// Load executes the statements of 'script', one per line, in s:
+//
+//	name = expr   [Scope.Set] the variable 'name' to the value of 'expr'
+//	import name   [Scope.Import] the scope libs[name] as 'name'
+//	import a.b    [Scope.ImportUnder] the scope libs["a.b"] as 'b' under 'a'
+//
+// Empty lines and lines starting with "//" are ignored. Bare expressions
+// are only accepted if s was configured with [Scope.WithTotal].
+//
+// Load stops at the first error, which reports its line number.
+// Statements before it have been executed.
+func (s *Scope) Load(script string, libs map[string]*Scope) error {
+	for n, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if err := s.exec(line, libs); err != nil {
+			return fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+	return nil
+}
+
+// exec executes a single statement of a script.
+func (s *Scope) exec(line string, libs map[string]*Scope) error {
+	if name, ok := strings.CutPrefix(line, "import "); ok {
+		name = strings.TrimSpace(name)
+		lib, ok := libs[name]
+		if !ok {
+			return fmt.Errorf("unknown import %q", name)
+		}
+		if root, name, ok := strings.Cut(name, "."); ok {
+			return s.ImportUnder(root, name, lib)
+		}
+		return s.Import(name, lib)
+	}
+	name, expr, ok := assignment(line)
+	if !ok && s.tally {
+		return s.add(line)
+	}
+	if !ok {
+		return fmt.Errorf("expected 'name = expr' or 'import name': %q", line)
+	}
+	return s.Set(name, expr)
+}
+
+// WithTotal returns a copy of s where [Scope.Load] also accepts bare
+// expressions, and adds their values to the variable 'total', for
+// receipt-style tallying:
+//
+//	price = 2.5
+//	3 * price
+//	1.25
+//
+// leaves 'total' at 8.75, see [Scope.Total]. 'total' is created by the
+// first bare expression. It is an ordinary variable: expressions can refer
+// to it, and assigning it explicitly, like "total = 0", sets the running
+// total, bare expressions keep adding to the assigned value. Assignments
+// to other variables do not change the total.
+func (s Scope) WithTotal() Scope {
+	s.tally = true
+	return s
+}
+
+// Total returns the value of the variable 'total' tallied by
+// [Scope.Load], see [Scope.WithTotal], or 0 if there is none.
+func (s Scope) Total() constant.Value {
+	if v, ok := s.Get("total"); ok {
+		return v
+	}
+	return constant.MakeInt64(0)
+}
+
+// add evaluates 'expr' and adds its value to the variable 'total'.
+func (s *Scope) add(expr string) error {
+	tv, err := s.check(expr)
+	if err != nil {
+		return err
+	}
+	if s.p != nil {
+		if total, ok := s.p.Scope().Lookup("total").(*types.Const); ok {
+			if tv, err = sum(types.TypeAndValue{Type: total.Type(), Value: total.Val()}, tv); err != nil {
+				return fmt.Errorf("cannot add %q to the total: %w", expr, err)
+			}
+		}
+	}
+	s.Delete("total")
+	s.assign("total", tv)
+	return nil
+}
+
+// sum returns 'a + b', with Go's rules for constants. It does not depend on
+// the options of s, like a modulus or disallowed operators: the total is
+// plain arithmetic.
+func sum(a, b types.TypeAndValue) (types.TypeAndValue, error) {
+	t := a.Type
+	switch {
+	case isUntyped(t):
+		t = b.Type
+	case !isUntyped(b.Type) && !types.Identical(t, b.Type):
+		return types.TypeAndValue{}, fmt.Errorf("mismatched types %v and %v", a.Type, b.Type)
+	}
+	numeric := func(v constant.Value) bool {
+		k := v.Kind()
+		return k == constant.Int || k == constant.Float || k == constant.Complex
+	}
+	strs := a.Value.Kind() == constant.String && b.Value.Kind() == constant.String
+	if !strs && !(numeric(a.Value) && numeric(b.Value)) {
+		return types.TypeAndValue{}, fmt.Errorf("cannot add %v to %v", b.Value, a.Value)
+	}
+	v := constant.BinaryOp(a.Value, token.ADD, b.Value)
+	if isUntyped(t) {
+		return types.TypeAndValue{Type: untyped(v), Value: v}, nil
+	}
+	if basic, ok := t.Underlying().(*types.Basic); ok && basic.Info()&types.IsNumeric != 0 {
+		if !fits(v, basic) {
+			return types.TypeAndValue{}, fmt.Errorf("%v overflows %v", v, t)
+		}
+		if basic.Info()&types.IsInteger != 0 {
+			v = constant.ToInt(v)
+		}
+	}
+	return types.TypeAndValue{Type: t, Value: v}, nil
+}
+
+// isUntyped reports whether 't' is the type of untyped constants.
+func isUntyped(t types.Type) bool {
+	b, ok := t.(*types.Basic)
+	return ok && b.Info()&types.IsUntyped != 0
+}
+
+// assignment splits a "name = expr" statement.
+func assignment(line string) (name, expr string, ok bool) {
+	name, expr, ok = strings.Cut(line, "=")
+	name = strings.TrimSpace(name)
+	if !ok || !token.IsIdentifier(name) || strings.HasPrefix(expr, "=") {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(expr), true
+}
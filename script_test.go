@@ -0,0 +1,151 @@
+package calc
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	var s Scope
+	err := s.Load(`
+// comment
+a = 1
+b = a + 1
+
+a = b * 10
+`, nil)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if a, _ := s.Int("a"); a != 20 {
+		t.Errorf("a = %v, want 20", a)
+	}
+
+	for _, script := range []string{
+		"a = 1\nb = 1 +",
+		"a = 1\nimport time",
+		"a = 1\na == 1",
+	} {
+		err := (&Scope{}).Load(script, nil)
+		if err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+			t.Errorf("Load(%q) error = %v, want a line 2 error", script, err)
+		}
+	}
+}
+
+func TestImportUnder(t *testing.T) {
+	var si, us Scope
+	si.Assign("K", "1000")
+	us.Assign("Mile", "1609.344")
+	s := Scope{}.WithRecording()
+	s.Assign("units", "2") // coexists with the root namespace.
+	if err := s.ImportUnder("units", "si", &si); err != nil {
+		t.Fatalf("ImportUnder() unexpected error: %v", err)
+	}
+	s.ImportUnder("units", "us", &us)
+	s.ImportUnder("units", "us", &si) // not replaced.
+
+	if got, err := s.Float64("units * units.si.K + units.us.Mile"); err != nil || got != 3609.344 {
+		t.Errorf("Float64() = %v, %v, want 3609.344", got, err)
+	}
+	if _, reads, err := s.EvalTracked("units.si.K"); err != nil || len(reads) != 1 || reads[0] != "units.si.K" {
+		t.Errorf("EvalTracked() = %v, %v, want [units.si.K]", reads, err)
+	}
+	if err := s.AllowedVars("units.si.K", nil); err != nil {
+		t.Errorf("AllowedVars() unexpected error: %v", err)
+	}
+	for _, expr := range []string{"units.si", "units.eu.K", "units.si.Missing"} {
+		if _, err := s.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) expected an error", expr)
+		}
+	}
+	if err := s.ImportUnder("Units", "si", &si); err == nil {
+		t.Errorf("ImportUnder() with an exported root expected an error")
+	}
+
+	// The script imports under the root too.
+	var c Scope
+	libs := map[string]*Scope{"units.si": &si, "units.us": &us}
+	if err := c.Load(s.Script(), libs); err != nil {
+		t.Fatalf("Load(%q) unexpected error: %v", s.Script(), err)
+	}
+	if got, err := c.Float64("units.si.K + units.us.Mile"); err != nil || got != 2609.344 {
+		t.Errorf("Float64() after Load = %v, %v, want 2609.344", got, err)
+	}
+}
+
+func TestWithTotal(t *testing.T) {
+	s := Scope{}.WithTotal()
+	if got := s.Total(); got.String() != "0" {
+		t.Errorf("Total() = %v, want 0", got)
+	}
+	err := s.Load(`
+price = 2.5
+3 * price
+1.25
+tax = total / 10
+tax
+`, nil)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if got := s.Total(); got.String() != "9.625" {
+		t.Errorf("Total() = %v, want 9.625", got)
+	}
+
+	if err := s.Load("total = 0\n2\n3", nil); err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if got := s.Total(); got.String() != "5" {
+		t.Errorf("Total() after a reset = %v, want 5", got)
+	}
+
+	err = s.Load("1\n\"a\"", nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Errorf("Load() error = %v, want a line 2 error", err)
+	}
+	if got := s.Total(); got.String() != "6" {
+		t.Errorf("Total() after an error = %v, want 6", got)
+	}
+
+	// The total does not depend on the options of s.
+	for _, c := range []Scope{Scope{}.WithTotal().WithDisallowedOps(token.ADD), Scope{}.WithTotal().WithModulus(7)} {
+		if err := c.Load("5\n4", nil); err != nil {
+			t.Errorf("Load() unexpected error: %v", err)
+		} else if got := c.Total(); got.String() != "9" {
+			t.Errorf("Total() = %v, want 9", got)
+		}
+	}
+	c := Scope{}.WithTotal()
+	if err := c.Load("int8(100)\nint8(100)", nil); err == nil {
+		t.Errorf("Load() of an overflowing int8 total expected an error")
+	}
+	if err := c.Load("int8(1)\nuint8(1)", nil); err == nil {
+		t.Errorf("Load() of mismatched types expected an error")
+	}
+	if err := c.Load("total = 0\n1\n0.5", nil); err != nil || c.Total().String() != "1.5" {
+		t.Errorf("Total() = %v, %v, want 1.5", c.Total(), err)
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	tests := []struct {
+		line, name, expr string
+		ok               bool
+	}{
+		{"x = 1 + 2", "x", "1 + 2", true},
+		{" rate=0.2 ", "rate", "0.2", true},
+		{"y = x == 1", "y", "x == 1", true},
+		{"x == 1", "", "", false},
+		{"x <= 1", "", "", false},
+		{"2 = x", "", "", false},
+		{"x + 1", "", "", false},
+	}
+	for _, test := range tests {
+		name, expr, ok := assignment(test.line)
+		if name != test.name || expr != test.expr || ok != test.ok {
+			t.Errorf("assignment(%q) = %q, %q, %v, want %q, %q, %v", test.line, name, expr, ok, test.name, test.expr, test.ok)
+		}
+	}
+}
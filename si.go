@@ -0,0 +1,216 @@
+package calc
+
+import (
+	"fmt"
+	"go/token"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// siPrefixes maps SI prefixes to their decimal exponent.
+var siPrefixes = []struct {
+	symbol string
+	exp    int
+}{
+	{"p", -12},
+	{"n", -9},
+	{"u", -6},
+	{"m", -3},
+	{"k", 3},
+	{"M", 6},
+	{"G", 9},
+	{"T", 12},
+}
+
+// siExponent returns the decimal exponent of the SI prefix 'symbol'.
+func siExponent(symbol string) (int, bool) {
+	if symbol == "µ" {
+		symbol = "u"
+	}
+	for _, p := range siPrefixes {
+		if p.symbol == symbol {
+			return p.exp, true
+		}
+	}
+	return 0, false
+}
+
+// WithSIPrefixes returns a copy of s where decimal numbers can be suffixed
+// with an SI prefix, as electronics users do:
+//
+//	p  1e-12
+//	n  1e-9
+//	u  1e-6 (µ is also accepted)
+//	m  1e-3
+//	k  1e3
+//	M  1e6
+//	G  1e9
+//	T  1e12
+//
+// So "4.7k" is 4700, "100n" is 1e-7, and "2M" is 2000000.
+//
+// The prefix can also replace the decimal point, as in resistor
+// notation: "4k7" is 4.7k, so 4700.
+//
+// The suffix must immediately follow the number, "2 m" is still the
+// variable 'm' after a 2. Hexadecimal, octal and binary literals are never
+// rewritten.
+func (s Scope) WithSIPrefixes() Scope {
+	s.si = true
+	return s
+}
+
+// rewriteSI rewrites SI-suffixed numbers in expr into scaled literals.
+func rewriteSI(expr string) string {
+	lexemes := scan(expr)
+	var b strings.Builder
+	last := 0
+	for i := 0; i+1 < len(lexemes); i++ {
+		num, suffix := lexemes[i], lexemes[i+1]
+		if num.tok != token.INT && num.tok != token.FLOAT || suffix.tok != token.IDENT || suffix.off != num.end || !isDecimal(num.lit) {
+			continue
+		}
+		// The prefix is either the whole identifier, or followed by the
+		// decimal digits (4k7).
+		symbol, digits := suffix.lit, ""
+		if i := strings.IndexFunc(suffix.lit, isDigit); i > 0 && num.tok == token.INT {
+			symbol, digits = suffix.lit[:i], suffix.lit[i:]
+			if strings.IndexFunc(digits, func(r rune) bool { return !isDigit(r) }) >= 0 {
+				continue
+			}
+		}
+		exp, ok := siExponent(symbol)
+		if !ok {
+			continue
+		}
+		lit := num.lit
+		if digits != "" {
+			lit += "." + digits
+		}
+		b.WriteString(expr[last:num.off])
+		b.WriteString("(" + lit + "*1e" + strconv.Itoa(exp) + ")")
+		last = suffix.end
+		i++
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
+
+// isDecimal reports whether the number literal 'lit' is written in base 10.
+func isDecimal(lit string) bool {
+	if len(lit) < 2 || lit[0] != '0' {
+		return true
+	}
+	switch lit[1] {
+	case 'x', 'X', 'b', 'B', 'o', 'O':
+		return false
+	}
+	return true
+}
+
+func isDigit(r rune) bool { return '0' <= r && r <= '9' }
+
+// Engineering evaluates 'expr' as a float and formats it in engineering
+// notation, using the SI prefixes accepted by [Scope.WithSIPrefixes]:
+// "4.7k", "1.5M", "100n".
+//
+// The exponent is computed exactly, and the mantissa is in [1, 1000): it
+// is the exact one rounded to a float64, and if it rounds to 1000 the next
+// prefix is used. Values outside of the SI prefixes range, even beyond the
+// range of float64, are formatted in scientific notation ("1e+15").
+func (s Scope) Engineering(expr string) (string, error) {
+	val, err := s.eval(expr)
+	if err != nil {
+		return "", err
+	}
+	r, ok := toRat(val)
+	if !ok {
+		return "", fmt.Errorf("not representable as a float (%v): %q", val.Kind(), expr)
+	}
+	if r.Sign() == 0 {
+		return "0", nil
+	}
+	sign, abs := "", new(big.Rat).Abs(r)
+	if r.Sign() < 0 {
+		sign = "-"
+	}
+	exp10 := exponent10(abs)
+	exp := exp10 - (exp10%3+3)%3 // rounded down to a multiple of 3.
+	m, _ := scale10(abs, exp).Float64()
+	if m >= 1000 {
+		exp += 3
+		m, _ = scale10(abs, exp).Float64()
+	}
+
+	symbol := ""
+	if exp != 0 {
+		ok := false
+		for _, p := range siPrefixes {
+			if p.exp == exp {
+				symbol, ok = p.symbol, true
+			}
+		}
+		if !ok {
+			// Scientific notation, with a mantissa in [1, 10).
+			m, _ := scale10(abs, exp10).Float64()
+			if m == 10 {
+				m, exp10 = 1, exp10+1
+			}
+			return sign + strconv.FormatFloat(m, 'g', -1, 64) + fmt.Sprintf("e%+03d", exp10), nil
+		}
+	}
+	return sign + strconv.FormatFloat(m, 'g', -1, 64) + symbol, nil
+}
+
+// SciParts evaluates 'expr' as a float, and returns it in scientific
+// notation: mantissa × 10^exponent, with 1 <= |mantissa| < 10. Zero is
+// 0 × 10^0, negative values have a negative mantissa.
+//
+// The exponent is computed exactly, even for values beyond the range of
+// float64, and the mantissa is the exact one rounded to a float64 (if it
+// rounds to 10, it is 1 and the exponent is incremented).
+func (s Scope) SciParts(expr string) (mantissa float64, exponent int, err error) {
+	r, err := s.rat(expr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if r.Sign() == 0 {
+		return 0, 0, nil
+	}
+	abs := new(big.Rat).Abs(r)
+	exponent = exponent10(abs)
+	mantissa, _ = scale10(abs, exponent).Float64()
+	if mantissa == 10 {
+		mantissa, exponent = 1, exponent+1
+	}
+	if r.Sign() < 0 {
+		mantissa = -mantissa
+	}
+	return mantissa, exponent, nil
+}
+
+// exponent10 returns the exponent of the leading digit of the positive
+// 'r': 10**e <= r < 10**(e+1).
+func exponent10(r *big.Rat) int {
+	// Estimate the exponent from the sizes, and adjust it exactly.
+	e := int(float64(r.Num().BitLen()-r.Denom().BitLen()) * math.Log10(2))
+	ten, one := big.NewRat(10, 1), big.NewRat(1, 1)
+	for scale10(r, e).Cmp(ten) >= 0 {
+		e++
+	}
+	for scale10(r, e).Cmp(one) < 0 {
+		e--
+	}
+	return e
+}
+
+// scale10 returns r / 10**e.
+func scale10(r *big.Rat, e int) *big.Rat {
+	p := new(big.Rat).SetInt(pow10(max(e, -e)))
+	if e < 0 {
+		return p.Mul(r, p)
+	}
+	return p.Quo(r, p)
+}
@@ -0,0 +1,31 @@
+package calc
+
+import "testing"
+
+func TestEngineering(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"4700", "4.7k"},
+		{"-0.000022", "-22u"},
+		{"1", "1"},
+		{"999", "999"},
+		{"999.9999999999999999999", "1k"},
+		{"1e15", "1e+15"},
+		{"1e400", "1e+400"},
+		{"-1e400", "-1e+400"},
+		{"1e-400", "1e-400"},
+		{"0", "0"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Engineering(test.expr)
+		if err != nil {
+			t.Errorf("Engineering(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Engineering(%q) = %q, want %q", test.expr, got, test.want)
+		}
+	}
+}
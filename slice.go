@@ -0,0 +1,119 @@
+package calc
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"maps"
+	"slices"
+)
+
+// AssignSlice defines the slice variable 'name', holding a copy of 'vs'.
+//
+// Slice variables can only be indexed: with 'data' assigned [1, 2, 3],
+// "data[2] + data[0]" is 4. The index can be any constant integer
+// expression, like "data[n-1]". Indices out of range are an error, unless
+// s counts from the end, see [Scope.WithNegativeIndices].
+//
+// Slice variables are apart from the other variables: they are not listed
+// by [Scope.Names], nor encoded by [Scope.MarshalText], nor compared by
+// [EqualScope]. [Scope.Delete] removes them. If the slice or map variable
+// 'name' already exists, it is replaced.
+func (s *Scope) AssignSlice(name string, vs []float64) {
+	s.arrays = maps.Clone(s.arrays) // copies of s are not changed.
+	if s.arrays == nil {
+		s.arrays = make(map[string][]float64)
+	}
+	s.deleteMap(name)
+	s.arrays[name] = slices.Clone(vs)
+}
+
+// AssignMap defines the map variable 'name', holding a copy of 'm'.
+//
+// Like slice variables (see [Scope.AssignSlice]), map variables can only
+// be indexed, by any constant string expression: with 'rate' assigned
+// {"eur": 1.1}, `100 * rate["eur"]` is 110. Missing keys are an error.
+//
+// Map variables are apart from the other variables, like slice variables.
+// If the slice or map variable 'name' already exists, it is replaced.
+func (s *Scope) AssignMap(name string, m map[string]float64) {
+	s.maps = maps.Clone(s.maps) // copies of s are not changed.
+	if s.maps == nil {
+		s.maps = make(map[string]map[string]float64)
+	}
+	s.deleteSlice(name)
+	s.maps[name] = maps.Clone(m)
+}
+
+// deleteSlice removes the slice variable 'name' from s, if it exists.
+func (s *Scope) deleteSlice(name string) {
+	if _, ok := s.arrays[name]; ok {
+		s.arrays = maps.Clone(s.arrays)
+		delete(s.arrays, name)
+	}
+}
+
+// deleteMap removes the map variable 'name' from s, if it exists.
+func (s *Scope) deleteMap(name string) {
+	if _, ok := s.maps[name]; ok {
+		s.maps = maps.Clone(s.maps)
+		delete(s.maps, name)
+	}
+}
+
+// isIndexed reports whether 'x' is the name of a slice or map variable of
+// s.
+func (s Scope) isIndexed(x ast.Expr) bool {
+	id, ok := x.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, array := s.arrays[id.Name]
+	_, dict := s.maps[id.Name]
+	return array || dict
+}
+
+// index replaces 'x' by the element it selects, if it indexes a slice or a
+// map variable of s.
+func (s Scope) index(e *evaluation, x ast.Expr) (ast.Expr, error) {
+	ix, ok := x.(*ast.IndexExpr)
+	if !ok || !s.isIndexed(ix.X) {
+		return x, nil
+	}
+	name := ix.X.(*ast.Ident).Name
+	val, err := e.value(ix.Index)
+	if err != nil {
+		return nil, err
+	}
+	var f float64
+	if m, ok := s.maps[name]; ok {
+		if val.Kind() != constant.String {
+			return nil, e.errorf(ix.Index.Pos(), "key %s is not a string", types.ExprString(ix.Index))
+		}
+		key := constant.StringVal(val)
+		if f, ok = m[key]; !ok {
+			return nil, e.errorf(ix.Index.Pos(), "key %q not found in %s", key, name)
+		}
+	} else {
+		vs := s.arrays[name]
+		i, exact := constant.Int64Val(constant.ToInt(val))
+		if !exact {
+			return nil, e.errorf(ix.Index.Pos(), "index %s is not an int", types.ExprString(ix.Index))
+		}
+		if s.negIndex && i < 0 {
+			i += int64(len(vs))
+		}
+		if i < 0 || i >= int64(len(vs)) {
+			if s.negIndex {
+				return nil, e.errorf(ix.Index.Pos(), "index %s out of range [%d, %d) for %s", val, -len(vs), len(vs), name)
+			}
+			return nil, e.errorf(ix.Index.Pos(), "index %s out of range [0, %d) for %s", val, len(vs), name)
+		}
+		f = vs[i]
+	}
+	v, t, _ := constantOf(f)
+	if v.Kind() == constant.Unknown {
+		return nil, e.errorf(ix.Pos(), "%s is not a finite number", types.ExprString(ix))
+	}
+	return e.bind(ix, types.TypeAndValue{Type: untypedOf(t), Value: v}), nil
+}
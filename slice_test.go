@@ -0,0 +1,108 @@
+package calc
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAssignSlice(t *testing.T) {
+	var s Scope
+	s.AssignValue("n", 3)
+	data := []float64{1.5, 2, 4}
+	s.AssignSlice("data", data)
+	data[0] = 100 // s holds a copy.
+	s.AssignSlice("bad", []float64{math.Inf(1)})
+
+	for _, tc := range []struct {
+		expr string
+		want float64
+		err  string
+	}{
+		{expr: "data[2] + data[0]", want: 5.5},
+		{expr: "data[n-1] * 2", want: 8},
+		{expr: "data[data[1]]", want: 4},
+		{expr: "data[3]", err: "index 3 out of range [0, 3) for data"},
+		{expr: "data[-1]", err: "index -1 out of range [0, 3) for data"},
+		{expr: "data[0.5]", err: "index 0.5 is not an int"},
+		{expr: "bad[0]", err: "bad[0] is not a finite number"},
+	} {
+		got, err := s.Float64(tc.expr)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Float64(%q) error = %v, want %q", tc.expr, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Float64(%q) unexpected error: %v", tc.expr, err)
+		} else if got != tc.want {
+			t.Errorf("Float64(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+
+	neg := s.WithNegativeIndices()
+	if got, err := neg.Float64("data[-1]"); err != nil || got != 4 {
+		t.Errorf("Float64(\"data[-1]\") with negative indices = %v, %v, want 4", got, err)
+	}
+	if _, err := neg.Float64("data[-4]"); err == nil || !strings.Contains(err.Error(), "out of range [-3, 3)") {
+		t.Errorf("Float64(\"data[-4]\") with negative indices error = %v", err)
+	}
+
+	if names := s.Names(); len(names) != 1 || names[0] != "n" {
+		t.Errorf("Names() = %v, want [n]", names)
+	}
+	c := s
+	c.Delete("data")
+	if _, err := c.Float64("data[0]"); err == nil {
+		t.Errorf("Float64(\"data[0]\") after Delete(\"data\") expected an error")
+	}
+	if _, err := s.Float64("data[0]"); err != nil {
+		t.Errorf("Delete(\"data\") changed a copy: %v", err)
+	}
+}
+
+func TestAssignMap(t *testing.T) {
+	var s Scope
+	s.AssignValue("cur", "eur")
+	rates := map[string]float64{"eur": 1.5, "usd": 1}
+	s.AssignMap("rate", rates)
+	rates["eur"] = 100 // s holds a copy.
+	s.AssignSlice("data", []float64{1, 2})
+	s.AssignMap("data", map[string]float64{"x": 3}) // replaces the slice.
+
+	for _, tc := range []struct {
+		expr string
+		want float64
+		err  string
+	}{
+		{expr: `100 * rate["eur"]`, want: 150},
+		{expr: `rate[cur] + rate["us" + "d"]`, want: 2.5},
+		{expr: `data["x"]`, want: 3},
+		{expr: `rate["gbp"]`, err: `key "gbp" not found in rate`},
+		{expr: "rate[1]", err: "key 1 is not a string"},
+		{expr: "data[0]", err: "key 0 is not a string"},
+	} {
+		got, err := s.Float64(tc.expr)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Float64(%q) error = %v, want %q", tc.expr, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Float64(%q) unexpected error: %v", tc.expr, err)
+		} else if got != tc.want {
+			t.Errorf("Float64(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+
+	c := s
+	c.Delete("rate")
+	if _, err := c.Float64(`rate["eur"]`); err == nil {
+		t.Errorf("Float64(`rate[\"eur\"]`) after Delete(\"rate\") expected an error")
+	}
+	if _, err := s.Float64(`rate["eur"]`); err != nil {
+		t.Errorf("Delete(\"rate\") changed a copy: %v", err)
+	}
+}
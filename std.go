@@ -0,0 +1,192 @@
+package calc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"math"
+	"strconv"
+)
+
+// StdScope returns an empty Scope where a curated set of functions of Go's
+// standard library is available under their usual names, on top of the
+// constants of [Scope.WithMathNamespace]. So Go snippets like
+//
+//	math.Sqrt(2) * math.Pi
+//	strconv.Atoi("42") + 1
+//
+// can be copied as is.
+//
+// Only pure functions over constants are supported: Go functions returning
+// an error return their result only, and an error stops the evaluation.
+// The available functions are:
+//
+//	math.Abs, math.Cbrt, math.Ceil, math.Cos, math.Exp, math.Floor,
+//	math.Hypot, math.Log, math.Log10, math.Log2, math.Max, math.Min,
+//	math.Mod, math.Pow, math.Round, math.Sin, math.Sqrt, math.Tan,
+//	math.Trunc, math.Atan, math.Atan2
+//	strconv.Atoi, strconv.Itoa, strconv.FormatInt, strconv.ParseFloat,
+//	strconv.Quote
+//
+// math functions compute in float64, like their Go counterparts. Variables
+// and imports named 'math' or 'strconv' take precedence over these
+// namespaces.
+func StdScope() Scope {
+	return Scope{math: true, std: true}
+}
+
+// stdlib are the functions of StdScope, by namespace.
+var stdlib = map[string]map[string]builtin{
+	"math": {
+		"Abs":   math1(math.Abs),
+		"Cbrt":  math1(math.Cbrt),
+		"Ceil":  math1(math.Ceil),
+		"Cos":   math1(math.Cos),
+		"Exp":   math1(math.Exp),
+		"Floor": math1(math.Floor),
+		"Hypot": math2(math.Hypot),
+		"Log":   math1(math.Log),
+		"Log10": math1(math.Log10),
+		"Log2":  math1(math.Log2),
+		"Max":   math2(math.Max),
+		"Min":   math2(math.Min),
+		"Mod":   math2(math.Mod),
+		"Pow":   math2(math.Pow),
+		"Round": math1(math.Round),
+		"Sin":   math1(math.Sin),
+		"Sqrt":  math1(math.Sqrt),
+		"Tan":   math1(math.Tan),
+		"Trunc": math1(math.Trunc),
+		"Atan":  math1(math.Atan),
+		"Atan2": math2(math.Atan2),
+	},
+	"strconv": {
+		"Atoi":       {nargs: 1, fn: atoi},
+		"Itoa":       {nargs: 1, fn: itoa},
+		"FormatInt":  {nargs: 2, fn: formatInt},
+		"ParseFloat": {nargs: 2, fn: parseFloat},
+		"Quote":      {nargs: 1, fn: quote},
+	},
+}
+
+// stdFunc returns the function of the standard library called by 'fun'.
+//
+// Like with the math namespace, variables and imports of the same name as
+// the namespace take precedence.
+func (e *evaluation) stdFunc(fun *ast.SelectorExpr) (builtin, bool) {
+	id, ok := fun.X.(*ast.Ident)
+	if !ok {
+		return builtin{}, false
+	}
+	if e.pkg != nil {
+		obj := e.pkg.Scope().Lookup(id.Name)
+		if pkg, ok := obj.(*types.PkgName); obj != nil && !(ok && pkg.Imported() == mathLib.p) {
+			return builtin{}, false
+		}
+	}
+	b, ok := stdlib[id.Name][fun.Sel.Name]
+	return b, ok
+}
+
+// math1 returns the builtin version of the float64 function 'f'.
+func math1(f func(float64) float64) builtin {
+	return builtin{nargs: 1, fn: func(args []constant.Value) (constant.Value, error) {
+		x, err := toFloat64(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fromFloat64(f(x))
+	}, float: always}
+}
+
+// math2 returns the builtin version of the float64 function 'f'.
+func math2(f func(float64, float64) float64) builtin {
+	return builtin{nargs: 2, fn: func(args []constant.Value) (constant.Value, error) {
+		x, err := toFloat64(args[0])
+		if err != nil {
+			return nil, err
+		}
+		y, err := toFloat64(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return fromFloat64(f(x, y))
+	}, float: always}
+}
+
+// stringArg returns the string value of 'v'.
+func stringArg(v constant.Value) (string, error) {
+	if v.Kind() != constant.String {
+		return "", fmt.Errorf("%v is not a string", v)
+	}
+	return constant.StringVal(v), nil
+}
+
+// intArg returns the int64 value of 'v'.
+func intArg(v constant.Value) (int64, error) {
+	i, ok := constant.Int64Val(constant.ToInt(v))
+	if !ok {
+		return 0, fmt.Errorf("%v is not an int", v)
+	}
+	return i, nil
+}
+
+func atoi(args []constant.Value) (constant.Value, error) {
+	str, err := stringArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	i, err := strconv.Atoi(str)
+	if err != nil {
+		return nil, err
+	}
+	return constant.MakeInt64(int64(i)), nil
+}
+
+func itoa(args []constant.Value) (constant.Value, error) {
+	i, err := intArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return constant.MakeString(strconv.FormatInt(i, 10)), nil
+}
+
+func formatInt(args []constant.Value) (constant.Value, error) {
+	i, err := intArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	base, err := intArg(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if base < 2 || base > 36 {
+		return nil, fmt.Errorf("invalid base %d", base)
+	}
+	return constant.MakeString(strconv.FormatInt(i, int(base))), nil
+}
+
+func parseFloat(args []constant.Value) (constant.Value, error) {
+	str, err := stringArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	bitSize, err := intArg(args[1])
+	if err != nil {
+		return nil, err
+	}
+	f, err := strconv.ParseFloat(str, int(bitSize))
+	if err != nil {
+		return nil, err
+	}
+	return fromFloat64(f)
+}
+
+func quote(args []constant.Value) (constant.Value, error) {
+	str, err := stringArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return constant.MakeString(strconv.Quote(str)), nil
+}
@@ -0,0 +1,52 @@
+package calc
+
+import "testing"
+
+func TestStdScope(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"math.Sqrt(16)", "4"},
+		{"math.Pow(2, 10)", "1024"},
+		{"math.Max(1, math.Abs(-3))", "3"},
+		{"math.Floor(2.5) + math.Ceil(2.5)", "5"},
+		{"math.Sqrt(4) * math.Pi == 2*math.Pi", "true"},
+		{`strconv.Atoi("42") + 1`, "43"},
+		{`strconv.Itoa(42) + "!"`, `"42!"`},
+		{`strconv.FormatInt(255, 16)`, `"ff"`},
+		{`strconv.ParseFloat("0.5", 64)`, "0.5"},
+		{`strconv.Quote("a")`, `"\"a\""`},
+		{"sqrt(16)", "4"},
+	}
+	s := StdScope()
+	for _, test := range tests {
+		got, err := s.Eval(test.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("Eval(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{`strconv.Atoi("x")`, "math.Sqrt(-1)", "math.Sqrt(1, 2)", "math.Cosh(1)"} {
+		if _, err := s.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) expected an error", expr)
+		}
+	}
+
+	// Outside of StdScope, functions are not available.
+	if _, err := (Scope{}).WithMathNamespace().Eval("math.Sqrt(4)"); err == nil {
+		t.Errorf("Eval(%q) expected an error outside of StdScope", "math.Sqrt(4)")
+	}
+
+	// User's imports take precedence.
+	var lib Scope
+	lib.Assign("Sqrt", "1")
+	s.Import("math", &lib)
+	if _, err := s.Eval("math.Sqrt(4)"); err == nil {
+		t.Errorf("Eval(%q) expected an error when math is imported", "math.Sqrt(4)")
+	}
+}
@@ -0,0 +1,83 @@
+package calc
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+)
+
+// Step is an intermediate step of an evaluation, see [Scope.EvalSteps].
+type Step struct {
+	Expr  string         // the sub-expression, as written.
+	Value constant.Value // its value.
+}
+
+// WithMaxSteps returns a copy of s where [Scope.EvalSteps] returns at most
+// the last 'n' steps, the ones closest to the result. If n is not positive,
+// all the steps are returned.
+func (s Scope) WithMaxSteps(n int) Scope {
+	s.maxSteps = max(n, 0)
+	return s
+}
+
+// EvalSteps evaluates 'expr' and returns its intermediate steps, for a
+// step-by-step display: with d = 86400, "2*d + 1" has the steps
+//
+//	2 * d     = 172800
+//	2 * d + 1 = 172801
+//
+// Each operation and function call is a step, in the order they are
+// computed, innermost first, and the last step is the result. Negative
+// literals like "-1", and sub-expressions without operations, like
+// variables or parentheses, are not steps, unless 'expr' is one.
+// Sub-expressions are formatted like [go/types.ExprString] does, and user
+// defined functions are replaced by their bodies.
+//
+// See [Scope.WithMaxSteps] to limit the number of steps.
+func (s Scope) EvalSteps(expr string) ([]Step, error) {
+	e := s.newEvaluation()
+	e.stepping = true
+	tv, err := s.checkIn(e, expr)
+	if err != nil {
+		return nil, err
+	}
+	steps := e.steps
+	if len(steps) == 0 {
+		steps = []Step{{expr, tv.Value}}
+	}
+	if s.maxSteps > 0 && len(steps) > s.maxSteps {
+		steps = steps[len(steps)-s.maxSteps:]
+	}
+	return steps, nil
+}
+
+// step records the step 'x', computed from the node 'orig', if e is
+// stepping.
+func (e *evaluation) step(orig, x ast.Expr) error {
+	if !e.stepping {
+		return nil
+	}
+	switch orig := orig.(type) {
+	case *ast.BinaryExpr, *ast.CallExpr:
+	case *ast.UnaryExpr:
+		if _, ok := orig.X.(*ast.BasicLit); ok {
+			return nil
+		}
+	case *ast.BasicLit:
+		if !e.tracing || orig.Kind != token.FLOAT {
+			return nil
+		}
+	default:
+		return nil
+	}
+	tv, err := e.checkConst(x)
+	if err != nil {
+		return err
+	}
+	if e.tracing {
+		return e.traceStep(orig, x, tv)
+	}
+	e.steps = append(e.steps, Step{types.ExprString(x), tv.Value})
+	return nil
+}
@@ -0,0 +1,41 @@
+package calc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEvalSteps(t *testing.T) {
+	var s Scope
+	s.Assign("d", "86400")
+	s.DefineFunc("sq", []string{"x"}, "x*x")
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"2*d + 1", "[{2 * d 172800} {2 * d + 1 172801}]"},
+		{"42", "[{42 42}]"},
+		{"d", "[{d 86400}]"},
+		{"-1 + -d", "[{-d -86400} {-1 + -d -86401}]"},
+		{"sqrt(16) * sq(3)", "[{sqrt(16) 4} {3 * 3 9} {sqrt(16) * (3 * 3) 36}]"},
+		{"(1 + 2)", "[{1 + 2 3}]"},
+	}
+	for _, test := range tests {
+		steps, err := s.EvalSteps(test.expr)
+		if err != nil {
+			t.Errorf("EvalSteps(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got := fmt.Sprint(steps); got != test.want {
+			t.Errorf("EvalSteps(%q) = %s, want %s", test.expr, got, test.want)
+		}
+	}
+
+	steps, err := s.WithMaxSteps(1).EvalSteps("2*d + 1")
+	if got := fmt.Sprint(steps); err != nil || got != "[{2 * d + 1 172801}]" {
+		t.Errorf("EvalSteps() with 1 step = %s, %v, want the result only", got, err)
+	}
+	if _, err := s.EvalSteps("1 + x"); err == nil {
+		t.Errorf("EvalSteps() expected an error")
+	}
+}
@@ -0,0 +1,87 @@
+package calc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Substitute replaces the identifiers of 'expr' found in 'repl' by their
+// replacement sub-expression.
+//
+// Substitution is done on the syntax tree, not on the text: only whole
+// identifiers are replaced (never the 'D' in 'time.D'), and replacements are
+// parenthesized when needed to preserve precedence. So substituting x by
+// "a+b" in "x*2" yields "(a+b)*2".
+//
+// Replacements are not substituted themselves. The rest of 'expr' is left
+// untouched.
+//
+// An error is returned if 'expr' or any of the replacements is not a valid
+// expression.
+func (s Scope) Substitute(expr string, repl map[string]string) (string, error) {
+	fset := token.NewFileSet()
+	x, err := parser.ParseExprFrom(fset, "", expr, 0)
+	if err != nil {
+		return "", parseError(err)
+	}
+	texts := make(map[string]string, len(repl))
+	for name, r := range repl {
+		rx, err := parser.ParseExpr(r)
+		if err != nil {
+			return "", fmt.Errorf("invalid replacement for %s: %w", name, err)
+		}
+		texts[name] = r
+		if !isOperand(rx) {
+			texts[name] = "(" + r + ")"
+		}
+	}
+
+	// Collect the identifiers to be replaced.
+	var (
+		idents []*ast.Ident
+		visit  func(n ast.Node) bool
+	)
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// Only the left part of a selector is a reference.
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.Ident:
+			idents = append(idents, n)
+		}
+		return true
+	}
+	ast.Inspect(x, visit)
+	sort.Slice(idents, func(i, j int) bool { return idents[i].Pos() < idents[j].Pos() })
+
+	var b strings.Builder
+	last := 0
+	file := fset.File(x.Pos())
+	for _, id := range idents {
+		text, ok := texts[id.Name]
+		if !ok {
+			continue
+		}
+		off := file.Offset(id.Pos())
+		b.WriteString(expr[last:off])
+		b.WriteString(text)
+		last = off + len(id.Name)
+	}
+	b.WriteString(expr[last:])
+	return b.String(), nil
+}
+
+// isOperand reports whether x can be used as an operand without
+// parentheses.
+func isOperand(x ast.Expr) bool {
+	switch x.(type) {
+	case *ast.Ident, *ast.BasicLit, *ast.ParenExpr, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package calc
+
+import "testing"
+
+func TestSubstitute(t *testing.T) {
+	tests := []struct {
+		expr string
+		repl map[string]string
+		want string
+	}{
+		{"x*2", map[string]string{"x": "a+b"}, "(a+b)*2"},
+		{"x*2", map[string]string{"x": "a"}, "a*2"},
+		{"-x", map[string]string{"x": "-3"}, "-(-3)"},
+		{"xx + x", map[string]string{"x": "1"}, "xx + 1"},
+		{"time.D + D", map[string]string{"D": "2", "time": "t"}, "t.D + 2"},
+		{"x + y", map[string]string{"x": "y", "y": "x"}, "y + x"},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Substitute(test.expr, test.repl)
+		if err != nil {
+			t.Errorf("Substitute(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Substitute(%q) = %q, want %q", test.expr, got, test.want)
+		}
+	}
+
+	if _, err := (Scope{}).Substitute("x+", nil); err == nil {
+		t.Errorf("Substitute(%q) expected an error", "x+")
+	}
+	if _, err := (Scope{}).Substitute("x", map[string]string{"x": "1+"}); err == nil {
+		t.Errorf("Substitute with invalid replacement expected an error")
+	}
+}
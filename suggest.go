@@ -0,0 +1,99 @@
+package calc
+
+import (
+	"errors"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// suggest completes "undefined: name" errors with the closest known name,
+// if any is close enough: "undefined: tiem (did you mean time?)".
+func (e *evaluation) suggest(err error) error {
+	var terr types.Error
+	if !errors.As(err, &terr) {
+		return err
+	}
+	name, ok := strings.CutPrefix(terr.Msg, "undefined: ")
+	if !ok || !isQualifiedIdent(name) {
+		return err
+	}
+
+	var candidates []string
+	if pkg, sel, ok := strings.Cut(name, "."); ok {
+		// Only exported names of the imported package.
+		var lib *types.Scope
+		if e.pkg != nil {
+			if pn, ok := e.pkg.Scope().Lookup(pkg).(*types.PkgName); ok {
+				lib = pn.Imported().Scope()
+			}
+		}
+		if lib == nil {
+			return err
+		}
+		for _, n := range lib.Names() {
+			if token.IsExported(n) {
+				candidates = append(candidates, pkg+"."+n)
+			}
+		}
+		name = pkg + "." + sel
+	} else {
+		if e.pkg != nil {
+			candidates = append(candidates, e.pkg.Scope().Names()...)
+		}
+		candidates = append(candidates, types.Universe.Names()...)
+		for n := range builtins {
+			candidates = append(candidates, n)
+		}
+	}
+
+	best, dist := "", max(1, len(name)/3)+1 // more edits would be a guess.
+	for _, c := range candidates {
+		if !isQualifiedIdent(c) {
+			continue // temporary variables.
+		}
+		if d := editDistance(name, c); d < dist || d == dist && best != "" && c < best {
+			best, dist = c, d
+		}
+	}
+	if best == "" {
+		return err
+	}
+	terr.Msg += " (did you mean " + best + "?)"
+	return terr
+}
+
+// isQualifiedIdent reports whether name is an identifier, or a qualified
+// identifier like "time.D".
+func isQualifiedIdent(name string) bool {
+	pkg, sel, ok := strings.Cut(name, ".")
+	return token.IsIdentifier(pkg) && (!ok || token.IsIdentifier(sel))
+}
+
+// editDistance returns the Levenshtein distance between a and b, where
+// the transposition of two adjacent characters counts as a single edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// d[i][j] is the distance between ra[:i] and rb[:j].
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,59 @@
+package calc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	var c, lib Scope
+	lib.Assign("Day", "86400")
+	lib.Assign("Hour", "3600")
+	c.Import("time", &lib)
+	c.Assign("speed", "3")
+
+	tests := []struct {
+		expr string
+		want string // suggestion, or "" if none.
+	}{
+		{"tiem.Day", "time"},
+		{"time.Dya", "time.Day"},
+		{"time.Hours", "time.Hour"},
+		{"2*sped", "speed"},
+		{"hypto(3, 4)", "hypot"},
+		{"flase", "false"},
+		{"xyz", ""},
+	}
+	for _, test := range tests {
+		_, err := c.Eval(test.expr)
+		if err == nil {
+			t.Errorf("Eval(%q) expected an error", test.expr)
+			continue
+		}
+		suggestion := ""
+		if _, s, ok := strings.Cut(err.Error(), "(did you mean "); ok {
+			suggestion = strings.TrimSuffix(s, "?)")
+		}
+		if suggestion != test.want {
+			t.Errorf("Eval(%q) = %v, want suggestion %q", test.expr, err, test.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"tiem", "time", 1},
+		{"flase", "false", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package calc
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Trace evaluates 'expr' and returns a log of its evaluation, to find
+// where exactness is lost: each step of [Scope.EvalSteps] and each float
+// literal is a line, with its exact value, and notes about float64:
+//
+//	0.1 = 1/10 (float64: 0.1, rounded)
+//	0.2 = 1/5 (float64: 0.2, rounded)
+//	0.1 + 0.2 = 3/10 (float64: 0.3, rounded; float64 arithmetic: 0.30000000000000004)
+//
+// The arithmetic of s is exact, but a value may not be exactly a float64:
+// it is then "rounded" to the nearest one, or "overflows". And computing
+// in float64 rounds each intermediate result, so errors accumulate:
+// "float64 arithmetic" is the value computed by the same operations in
+// float64, when it differs from the nearest float64 of the exact value.
+// Builtin functions going through float64, like sqrt or pow with a
+// fractional exponent, are "computed in float64": their results are not
+// exact.
+//
+// Values are written exactly, as integers or fractions, unless too long.
+func (s Scope) Trace(expr string) (string, error) {
+	e := s.newEvaluation()
+	e.stepping, e.tracing = true, true
+	e.floats = make(map[ast.Expr]float64)
+	tv, err := s.checkIn(e, expr)
+	if err != nil {
+		return "", err
+	}
+	if len(e.trace) == 0 {
+		e.trace = []string{expr + " = " + exactString(tv.Value)}
+	}
+	return strings.Join(e.trace, "\n"), nil
+}
+
+// traceStep logs the step 'x' of value 'tv', computed from the node 'orig'.
+func (e *evaluation) traceStep(orig, x ast.Expr, tv types.TypeAndValue) error {
+	line := types.ExprString(x) + " = " + exactString(tv.Value)
+	if k := tv.Value.Kind(); k != constant.Int && k != constant.Float && k != constant.Bool {
+		e.trace = append(e.trace, line)
+		return nil
+	}
+	var notes []string
+	near, exact := constant.Float64Val(constant.ToFloat(tv.Value))
+	if call, ok := orig.(*ast.CallExpr); ok && e.inFloat64(call) {
+		notes = append(notes, "computed in float64")
+	} else if math.IsInf(near, 0) {
+		notes = append(notes, "float64: overflows")
+	} else if !exact && tv.Value.Kind() != constant.Bool {
+		notes = append(notes, "float64: "+formatFloat(near)+", rounded")
+	}
+	// The same operation, in float64.
+	if bin, ok := orig.(*ast.BinaryExpr); ok && e.isFloat(bin.X, bin.Y) {
+		fx, err := e.float(bin.X)
+		if err != nil {
+			return err
+		}
+		fy, err := e.float(bin.Y)
+		if err != nil {
+			return err
+		}
+		switch bin.Op {
+		case token.ADD, token.SUB, token.MUL, token.QUO:
+			f := floatOp(fx, bin.Op, fy)
+			e.floats[x] = f
+			if f != near {
+				notes = append(notes, "float64 arithmetic: "+formatFloat(f))
+			}
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			cmp := constant.Compare(constant.MakeFloat64(fx), bin.Op, constant.MakeFloat64(fy))
+			if cmp != constant.BoolVal(tv.Value) {
+				notes = append(notes, "float64 arithmetic: "+strconv.FormatBool(cmp))
+			}
+		}
+	}
+	if len(notes) > 0 {
+		line += " (" + strings.Join(notes, "; ") + ")"
+	}
+	e.trace = append(e.trace, line)
+	return nil
+}
+
+// inFloat64 reports whether 'call' is a call to a builtin function that
+// computes in float64, given its folded arguments.
+func (e *evaluation) inFloat64(call *ast.CallExpr) bool {
+	_, b, ok := e.builtin(call.Fun)
+	if !ok || b.float == nil || e.prec > 0 && b.big != nil {
+		return false
+	}
+	args := make([]constant.Value, len(call.Args))
+	for i, arg := range call.Args {
+		v, err := e.value(arg)
+		if err != nil {
+			return false
+		}
+		args[i] = v
+	}
+	return b.float(args)
+}
+
+// isFloat reports whether any of 'xs' has a float value.
+func (e *evaluation) isFloat(xs ...ast.Expr) bool {
+	for _, x := range xs {
+		if tv, err := e.checkConst(x); err == nil && tv.Value.Kind() == constant.Float {
+			return true
+		}
+	}
+	return false
+}
+
+// float returns the value of 'x' computed in float64.
+func (e *evaluation) float(x ast.Expr) (float64, error) {
+	if p, ok := x.(*ast.ParenExpr); ok {
+		return e.float(p.X)
+	}
+	if f, ok := e.floats[x]; ok {
+		return f, nil
+	}
+	v, err := e.value(x)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := constant.Float64Val(constant.ToFloat(v))
+	return f, nil
+}
+
+// floatOp returns 'x op y', for an arithmetic operator 'op'.
+func floatOp(x float64, op token.Token, y float64) float64 {
+	switch op {
+	case token.ADD:
+		return x + y
+	case token.SUB:
+		return x - y
+	case token.MUL:
+		return x * y
+	}
+	return x / y
+}
+
+// formatFloat returns the shortest notation of 'f'.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// exactString returns the exact notation of 'v', or its short notation if
+// the exact one is too long to be read.
+func exactString(v constant.Value) string {
+	if str := v.ExactString(); len(str) <= 40 {
+		return str
+	}
+	return v.String()
+}
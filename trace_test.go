@@ -0,0 +1,46 @@
+package calc
+
+import "testing"
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"0.1 + 0.2 == 0.3", `0.1 = 1/10 (float64: 0.1, rounded)
+0.2 = 1/5 (float64: 0.2, rounded)
+0.1 + 0.2 = 3/10 (float64: 0.3, rounded; float64 arithmetic: 0.30000000000000004)
+0.3 = 3/10 (float64: 0.3, rounded)
+0.1 + 0.2 == 0.3 = true (float64 arithmetic: false)`},
+		{"(0.5 + 0.25) * 4", `0.5 = 1/2
+0.25 = 1/4
+0.5 + 0.25 = 3/4
+(0.5 + 0.25) * 4 = 3`},
+		{"sqrt(2) / 2", `sqrt(2) = 6369051672525773/4503599627370496 (computed in float64)
+sqrt(2) / 2 = 6369051672525773/9007199254740992`},
+		{"1e400 / 3", `1e400 = 1e+400 (float64: overflows)
+1e400 / 3 = 3.33333e+399 (float64: overflows)`},
+		{"pow(2, 10)", "pow(2, 10) = 1024"},
+		{"pow(2, 0.5)", `0.5 = 1/2
+pow(2, 0.5) = 6369051672525773/4503599627370496 (computed in float64)`},
+		{"abs(-0.1)", `0.1 = 1/10 (float64: 0.1, rounded)
+abs(-0.1) = 1/10 (float64: 0.1, rounded)`},
+		{"abs(1+1i)", `1 + 1i = (1 + 1i)
+abs(1 + 1i) = 6369051672525773/4503599627370496 (computed in float64)`},
+		{"7 / 2", "7 / 2 = 3"},
+		{`"a"`, `"a" = "a"`},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.Trace(test.expr)
+		if err != nil {
+			t.Errorf("Trace(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Trace(%q) =\n%s\nwant\n%s", test.expr, got, test.want)
+		}
+	}
+	if _, err := (Scope{}).Trace("1 +"); err == nil {
+		t.Errorf("Trace() expected an error")
+	}
+}
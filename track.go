@@ -0,0 +1,55 @@
+package calc
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"slices"
+)
+
+// EvalTracked is like [Scope.Eval], but it also returns the names of the
+// variables read by 'expr', in the order they are first read, for instance
+// to build the dependency graph of a spreadsheet.
+//
+// Variables of imports are reported qualified, like "units.K", and so are
+// the ones of the math namespace ("math.Pi"). Variables read by the bodies
+// of user-defined functions are reported too, but not the parameters of
+// these functions, nor predeclared identifiers like true.
+func (s Scope) EvalTracked(expr string) (constant.Value, []string, error) {
+	e := s.newEvaluation()
+	e.tracking = true
+	tv, err := s.checkIn(e, expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tv.Value, e.reads, nil
+}
+
+// track records the variable read by 'x', if any, and if e is tracking.
+func (e *evaluation) track(x ast.Expr) {
+	if !e.tracking || e.pkg == nil {
+		return
+	}
+	var name string
+	switch x := x.(type) {
+	case *ast.Ident:
+		if _, ok := e.pkg.Scope().Lookup(x.Name).(*types.Const); ok {
+			name = x.Name
+		}
+	case *ast.SelectorExpr:
+		id, ok := x.X.(*ast.Ident)
+		if !ok {
+			return
+		}
+		pkg, ok := e.pkg.Scope().Lookup(id.Name).(*types.PkgName)
+		if !ok {
+			return
+		}
+		if _, ok := pkg.Imported().Scope().Lookup(x.Sel.Name).(*types.Const); ok {
+			name = id.Name + "." + x.Sel.Name
+		}
+	}
+	if name != "" && !slices.Contains(e.reads, name) {
+		e.reads = append(e.reads, name)
+	}
+}
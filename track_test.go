@@ -0,0 +1,45 @@
+package calc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEvalTracked(t *testing.T) {
+	var lib Scope
+	lib.Assign("K", "1000")
+	var s Scope
+	s.Assign("a", "1")
+	s.Assign("b", "2")
+	s.Assign("rate", "3")
+	s.Import("units", &lib)
+	s.DefineFunc("taxed", []string{"x"}, "x * rate")
+	s = s.WithMathNamespace()
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"b + a*b", []string{"b", "a"}},
+		{"sqrt(a) + units.K", []string{"a", "units.K"}},
+		{"taxed(b)", []string{"b", "rate"}},
+		{"math.Pi * a", []string{"math.Pi", "a"}},
+		{"1 + 2 == 3 && true", nil},
+	}
+	for _, test := range tests {
+		_, got, err := s.EvalTracked(test.expr)
+		if err != nil {
+			t.Errorf("EvalTracked(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("EvalTracked(%q) = %q, want %q", test.expr, got, test.want)
+		}
+	}
+	if v, _, err := s.EvalTracked("a + b"); err != nil || v.String() != "3" {
+		t.Errorf("EvalTracked() = %v, %v, want 3", v, err)
+	}
+	if _, _, err := s.EvalTracked("a + c"); err == nil {
+		t.Errorf("EvalTracked() expected an error")
+	}
+}
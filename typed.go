@@ -0,0 +1,206 @@
+package calc
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+)
+
+// AssignTyped is like [Scope.AssignValue], but the variable 'name' keeps
+// the Go type of 'v': an int32 is an int32 constant, not an untyped one.
+//
+// Like in Go, expressions involving typed variables are typed too, and
+// must fit in their type: if 'x' is an int32, "x << 40" is an error. And
+// the width-checked accessors ([Scope.Int8], [Scope.Uint16], ...) refuse
+// typed results wider than the requested type, even if the value would
+// fit: with 'x' an int32 holding 1, Int8("x") is an error. Untyped
+// results, like those of [Scope.AssignValue] variables, are only checked
+// against the range of the requested type.
+//
+// If the variable 'name' already exists, its value is not changed.
+func (s *Scope) AssignTyped(name string, v any) {
+	val, t, ok := constantOf(v)
+	if !ok {
+		panic(fmt.Sprintf("unsupported type %T", v))
+	}
+	s.assign(name, types.TypeAndValue{Type: t, Value: val})
+}
+
+// Int8 evaluates 'expr' as an int8.
+func (s Scope) Int8(expr string) (int8, error) {
+	v, err := s.fixedInt(expr, types.Typ[types.Int8])
+	if err != nil {
+		return 0, err
+	}
+	i, _ := constant.Int64Val(v)
+	return int8(i), nil
+}
+
+// Int16 evaluates 'expr' as an int16.
+func (s Scope) Int16(expr string) (int16, error) {
+	v, err := s.fixedInt(expr, types.Typ[types.Int16])
+	if err != nil {
+		return 0, err
+	}
+	i, _ := constant.Int64Val(v)
+	return int16(i), nil
+}
+
+// Int32 evaluates 'expr' as an int32.
+func (s Scope) Int32(expr string) (int32, error) {
+	v, err := s.fixedInt(expr, types.Typ[types.Int32])
+	if err != nil {
+		return 0, err
+	}
+	i, _ := constant.Int64Val(v)
+	return int32(i), nil
+}
+
+// Uint8 evaluates 'expr' as a uint8.
+func (s Scope) Uint8(expr string) (uint8, error) {
+	v, err := s.fixedInt(expr, types.Typ[types.Uint8])
+	if err != nil {
+		return 0, err
+	}
+	u, _ := constant.Uint64Val(v)
+	return uint8(u), nil
+}
+
+// Uint16 evaluates 'expr' as a uint16.
+func (s Scope) Uint16(expr string) (uint16, error) {
+	v, err := s.fixedInt(expr, types.Typ[types.Uint16])
+	if err != nil {
+		return 0, err
+	}
+	u, _ := constant.Uint64Val(v)
+	return uint16(u), nil
+}
+
+// Uint32 evaluates 'expr' as a uint32.
+func (s Scope) Uint32(expr string) (uint32, error) {
+	v, err := s.fixedInt(expr, types.Typ[types.Uint32])
+	if err != nil {
+		return 0, err
+	}
+	u, _ := constant.Uint64Val(v)
+	return uint32(u), nil
+}
+
+// sizes are the sizes of Go types, int and uint are 64 bits wide.
+var sizes = types.SizesFor("gc", "amd64")
+
+// fixedInt evaluates 'expr' as an integer of the basic type 't'.
+//
+// Typed results wider than 't' are refused.
+func (s Scope) fixedInt(expr string, t *types.Basic) (constant.Value, error) {
+	tv, err := s.check(expr)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := tv.Type.(*types.Basic); ok && b.Info()&types.IsUntyped == 0 && sizes.Sizeof(b) > sizes.Sizeof(t) {
+		return nil, fmt.Errorf("%v is wider than %v: %q", b, t, expr)
+	}
+	ival := constant.ToInt(tv.Value)
+	if ival.Kind() == constant.Unknown {
+		return nil, fmt.Errorf("not representable as an int (%v): %q", tv.Value.Kind(), expr)
+	}
+	if !inRange(ival, t) {
+		return nil, fmt.Errorf("not exactly representable as an %v: %q", t, expr)
+	}
+	return ival, nil
+}
+
+// inRange reports whether the integer 'v' is in the range of the integer
+// type 't'.
+func inRange(v constant.Value, t *types.Basic) bool {
+	bits := uint(8 * sizes.Sizeof(t))
+	one := constant.MakeInt64(1)
+	lo, hi := constant.MakeInt64(0), constant.BinaryOp(constant.Shift(one, token.SHL, bits), token.SUB, one)
+	if t.Info()&types.IsUnsigned == 0 {
+		lo = constant.UnaryOp(token.SUB, constant.Shift(one, token.SHL, bits-1), 0)
+		hi = constant.BinaryOp(constant.Shift(one, token.SHL, bits-1), token.SUB, one)
+	}
+	return constant.Compare(lo, token.LEQ, v) && constant.Compare(v, token.LEQ, hi)
+}
+
+// FitsIn evaluates 'expr', and reports whether its value can be converted
+// to the Go numeric type named 'goType' ("int8", "uint16", "float32",
+// "complex64", "byte", ...) like a Go constant conversion: integer types
+// require an integral value in their range, so "3.0" fits in an int8 but
+// not "3.5" nor "200". Float and complex types only require values not to
+// overflow, rounding is fine: "0.1" fits in a float32, but not "1e39".
+//
+// Strings and booleans do not fit in numeric types. An error is returned
+// if 'goType' is not a numeric type, or if 'expr' cannot be evaluated.
+func (s Scope) FitsIn(expr, goType string) (bool, error) {
+	var t *types.Basic
+	if obj, ok := types.Universe.Lookup(goType).(*types.TypeName); ok {
+		t, _ = obj.Type().(*types.Basic)
+	}
+	if t == nil || t.Info()&types.IsNumeric == 0 {
+		return false, fmt.Errorf("not a numeric type: %s", goType)
+	}
+	val, err := s.eval(expr)
+	if err != nil {
+		return false, err
+	}
+	return fits(val, t), nil
+}
+
+// fits reports whether 'val' can be converted to the numeric type 't'.
+func fits(val constant.Value, t *types.Basic) bool {
+	switch info := t.Info(); {
+	case info&types.IsInteger != 0:
+		ival := constant.ToInt(val)
+		return ival.Kind() == constant.Int && inRange(ival, t)
+	case info&types.IsFloat != 0:
+		return fitsFloat(constant.ToFloat(val), t.Kind() == types.Float32)
+	default:
+		cval := constant.ToComplex(val)
+		if cval.Kind() != constant.Complex {
+			return false
+		}
+		f32 := t.Kind() == types.Complex64
+		return fitsFloat(constant.Real(cval), f32) && fitsFloat(constant.Imag(cval), f32)
+	}
+}
+
+// fitsFloat reports whether the float 'v' does not overflow a float64, or a
+// float32 if 'f32'.
+func fitsFloat(v constant.Value, f32 bool) bool {
+	if v.Kind() != constant.Float && v.Kind() != constant.Int {
+		return false
+	}
+	if f32 {
+		f, _ := constant.Float32Val(v)
+		return !math.IsInf(float64(f), 0)
+	}
+	f, _ := constant.Float64Val(v)
+	return !math.IsInf(f, 0)
+}
+
+// SmallestInt evaluates 'expr' as an int64, and returns the name of the
+// narrowest Go integer type that holds it.
+//
+// Non-negative values get an unsigned type (uint8, uint16, uint32 or
+// uint64), and negative ones a signed type (int8, int16, int32 or int64):
+// 200 is a uint8, and -200 an int16.
+func (s Scope) SmallestInt(expr string) (value int64, typeName string, err error) {
+	value, err = s.Int(expr)
+	if err != nil {
+		return 0, "", err
+	}
+	kinds := []types.BasicKind{types.Uint8, types.Uint16, types.Uint32, types.Uint64}
+	if value < 0 {
+		kinds = []types.BasicKind{types.Int8, types.Int16, types.Int32, types.Int64}
+	}
+	v := constant.MakeInt64(value)
+	for _, k := range kinds {
+		if t := types.Typ[k]; inRange(v, t) {
+			return value, t.Name(), nil
+		}
+	}
+	panic("unreachable: int64 values fit in an int64 or a uint64")
+}
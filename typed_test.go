@@ -0,0 +1,150 @@
+package calc
+
+import "testing"
+
+func TestAssignTyped(t *testing.T) {
+	var c Scope
+	c.AssignTyped("r32", int32(1))
+	c.AssignTyped("r8", int8(1))
+	c.AssignTyped("u8", uint8(200))
+	c.AssignValue("x", int32(1))
+
+	if _, err := c.Int8("r32"); err == nil {
+		t.Errorf("Int8(%q) expected an error", "r32")
+	}
+	if v, err := c.Int8("r8 + 1"); err != nil || v != 2 {
+		t.Errorf("Int8(%q) = %v, %v, want 2", "r8 + 1", v, err)
+	}
+	if v, err := c.Int8("x"); err != nil || v != 1 {
+		t.Errorf("Int8(%q) = %v, %v, want 1", "x", v, err)
+	}
+	if v, err := c.Int32("r8"); err != nil || v != 1 {
+		t.Errorf("Int32(%q) = %v, %v, want 1", "r8", v, err)
+	}
+	if _, err := c.Int8("u8"); err == nil {
+		t.Errorf("Int8(%q) expected an error", "u8")
+	}
+	if v, err := c.Uint8("u8"); err != nil || v != 200 {
+		t.Errorf("Uint8(%q) = %v, %v, want 200", "u8", v, err)
+	}
+	// Typed arithmetic must fit the type.
+	if _, err := c.Eval("u8 + 100"); err == nil {
+		t.Errorf("Eval(%q) expected an overflow error", "u8 + 100")
+	}
+	if _, err := c.Eval("x + 1<<40"); err != nil {
+		t.Errorf("Eval(%q) unexpected error: %v", "x + 1<<40", err)
+	}
+	if _, err := c.Eval("r32 + 1<<40"); err == nil {
+		t.Errorf("Eval(%q) expected an overflow error", "r32 + 1<<40")
+	}
+}
+
+func TestFixedInt(t *testing.T) {
+	tests := []struct {
+		expr string
+		ok   [6]bool // Int8, Int16, Int32, Uint8, Uint16, Uint32
+	}{
+		{"127", [6]bool{true, true, true, true, true, true}},
+		{"-128", [6]bool{true, true, true, false, false, false}},
+		{"128", [6]bool{false, true, true, true, true, true}},
+		{"255", [6]bool{false, true, true, true, true, true}},
+		{"256", [6]bool{false, true, true, false, true, true}},
+		{"1<<31", [6]bool{false, false, false, false, false, true}},
+		{"1<<32", [6]bool{false, false, false, false, false, false}},
+		{"-1<<31", [6]bool{false, false, true, false, false, false}},
+		{"2.5", [6]bool{}},
+	}
+	var c Scope
+	for _, test := range tests {
+		_, err8 := c.Int8(test.expr)
+		_, err16 := c.Int16(test.expr)
+		_, err32 := c.Int32(test.expr)
+		_, erru8 := c.Uint8(test.expr)
+		_, erru16 := c.Uint16(test.expr)
+		_, erru32 := c.Uint32(test.expr)
+		for i, err := range []error{err8, err16, err32, erru8, erru16, erru32} {
+			if (err == nil) != test.ok[i] {
+				t.Errorf("%q accessor #%d: got error %v, want ok=%v", test.expr, i, err, test.ok[i])
+			}
+		}
+	}
+}
+
+func TestFitsIn(t *testing.T) {
+	tests := []struct {
+		expr, goType string
+		want         bool
+	}{
+		{"127", "int8", true},
+		{"128", "int8", false},
+		{"-128", "int8", true},
+		{"3.0", "int8", true},
+		{"3.5", "int8", false},
+		{"255", "byte", true},
+		{"-1", "uint", false},
+		{"1<<63", "uint64", true},
+		{"1<<63", "int", false},
+		{"0x10FFFF", "rune", true},
+		{"1<<64", "uintptr", false},
+		{"0.1", "float32", true},
+		{"1e39", "float32", false},
+		{"1e39", "float64", true},
+		{"1e309", "float64", false},
+		{"1 + 2i", "complex64", true},
+		{"1e39i", "complex64", false},
+		{"1e39i", "complex128", true},
+		{"1i", "float64", false},
+		{"1i", "int", false},
+		{`"1"`, "int", false},
+		{"true", "uint8", false},
+	}
+	for _, test := range tests {
+		got, err := Scope{}.FitsIn(test.expr, test.goType)
+		if err != nil {
+			t.Errorf("FitsIn(%q, %q) unexpected error: %v", test.expr, test.goType, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("FitsIn(%q, %q) = %v, want %v", test.expr, test.goType, got, test.want)
+		}
+	}
+	for _, goType := range []string{"string", "bool", "error", "int128", "len"} {
+		if _, err := (Scope{}).FitsIn("1", goType); err == nil {
+			t.Errorf("FitsIn(\"1\", %q) expected an error", goType)
+		}
+	}
+}
+
+func TestSmallestInt(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"0", "uint8"},
+		{"255", "uint8"},
+		{"256", "uint16"},
+		{"1<<32 - 1", "uint32"},
+		{"1 << 32", "uint64"},
+		{"-1", "int8"},
+		{"-128", "int8"},
+		{"-129", "int16"},
+		{"-200", "int16"},
+		{"-1 << 31", "int32"},
+		{"-1<<31 - 1", "int64"},
+	}
+	for _, test := range tests {
+		_, got, err := Scope{}.SmallestInt(test.expr)
+		if err != nil {
+			t.Errorf("SmallestInt(%q) unexpected error: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("SmallestInt(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+	for _, expr := range []string{"1 << 63", "1.5", `"a"`} {
+		if _, _, err := (Scope{}).SmallestInt(expr); err == nil {
+			t.Errorf("SmallestInt(%q) expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package calc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"maps"
+	"strconv"
+	"sync"
+)
+
+// unit is a unit of measure: a dimension, and the factor converting a
+// quantity in this unit to the base unit of the dimension.
+type unit struct {
+	dim    string
+	factor constant.Value
+}
+
+var (
+	unitsMu sync.RWMutex
+	units   = map[string]unit{}
+)
+
+func init() {
+	for _, def := range [][3]string{
+		// Lengths, in meters.
+		{"m", "length", "1"},
+		{"km", "length", "1000"},
+		{"cm", "length", "1/100."},
+		{"mm", "length", "1/1000."},
+		{"in", "length", "0.0254"},
+		{"ft", "length", "0.3048"},
+		{"yd", "length", "0.9144"},
+		{"mi", "length", "1609.344"},
+		// Times, in seconds.
+		{"s", "time", "1"},
+		{"ms", "time", "1/1000."},
+		{"min", "time", "60"},
+		{"h", "time", "3600"},
+		{"d", "time", "86400"},
+		// Masses, in kilograms.
+		{"kg", "mass", "1"},
+		{"g", "mass", "1/1000."},
+		{"t", "mass", "1000"},
+		{"lb", "mass", "0.45359237"},
+		{"oz", "mass", "0.45359237/16"},
+	} {
+		if err := RegisterUnit(def[0], def[1], def[2]); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterUnit adds the unit 'name' of the dimension 'dim' to the
+// conversion table used by [Scope.InUnit]. 'factor' is an expression of the
+// value of one 'name' in the base unit of 'dim', whose own factor is 1.
+//
+// Builtin units are m, km, cm, mm, in, ft, yd and mi for the "length"
+// dimension (in meters), s, ms, min, h and d for "time" (in seconds), and
+// kg, g, t, lb and oz for "mass" (in kilograms).
+func RegisterUnit(name, dim, factor string) error {
+	f, err := Scope{}.eval(factor)
+	if err != nil {
+		return err
+	}
+	if constant.ToFloat(f).Kind() == constant.Unknown || constant.Sign(f) <= 0 {
+		return fmt.Errorf("invalid unit factor %v", f)
+	}
+	unitsMu.Lock()
+	defer unitsMu.Unlock()
+	if _, ok := units[name]; ok {
+		return fmt.Errorf("unit %q already registered", name)
+	}
+	units[name] = unit{dim, f}
+	return nil
+}
+
+// lookupUnit returns the unit 'name'.
+func lookupUnit(name string) (unit, error) {
+	unitsMu.RLock()
+	defer unitsMu.RUnlock()
+	u, ok := units[name]
+	if !ok {
+		return unit{}, fmt.Errorf("unknown unit %q", name)
+	}
+	return u, nil
+}
+
+// AssignUnitExpr is like [Scope.Assign], but the variable 'name' is a
+// quantity in 'unit', see [Scope.InUnit]. The variable itself holds the
+// value of 'valueExpr', in 'unit'.
+func (s *Scope) AssignUnitExpr(name, valueExpr, unit string) error {
+	if _, err := lookupUnit(unit); err != nil {
+		return err
+	}
+	if s.p != nil && s.p.Scope().Lookup(name) != nil {
+		return nil // like Assign, existing variables are not changed.
+	}
+	if err := s.Assign(name, valueExpr); err != nil {
+		return err
+	}
+	s.units = maps.Clone(s.units) // copies of s are not changed.
+	if s.units == nil {
+		s.units = make(map[string]string)
+	}
+	s.units[name] = unit
+	return nil
+}
+
+// InUnit evaluates 'expr', a quantity, as a float64 in the unit
+// 'targetUnit': with 'x' assigned 1 in "ft", InUnit("2*x", "m") is 0.6096.
+//
+// Units are not tracked through operations: all the variables with a unit
+// in 'expr' must have the dimension of 'targetUnit', and the result is
+// assumed to have this dimension too. They are converted to the base unit
+// before evaluation, so "x + y" is fine with 'x' in feet and 'y' in meters.
+// But "x * y" is not an area, it is an error to convert it to a length. An
+// expression without quantities is an error too.
+func (s Scope) InUnit(expr, targetUnit string) (float64, error) {
+	target, err := lookupUnit(targetUnit)
+	if err != nil {
+		return 0, err
+	}
+	x, _, err := s.Parse(expr)
+	if err != nil {
+		return 0, err
+	}
+	// Quantities are converted to the base unit in a copy of s.
+	c := s.clone()
+	converted := make(map[string]bool)
+	var convert func(n ast.Node) bool
+	convert = func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			ast.Inspect(sel.X, convert) // not the selected name.
+			return false
+		}
+		id, ok := n.(*ast.Ident)
+		if !ok || err != nil || converted[id.Name] {
+			return err == nil
+		}
+		name, ok := s.units[id.Name]
+		if !ok {
+			return true
+		}
+		var u unit
+		if u, err = lookupUnit(name); err != nil {
+			return false
+		}
+		if u.dim != target.dim {
+			err = fmt.Errorf("cannot convert %s (%s) to %s (%s)", id.Name, u.dim, targetUnit, target.dim)
+			return false
+		}
+		v, _ := c.Get(id.Name)
+		v = constant.BinaryOp(v, token.MUL, u.factor)
+		c.Delete(id.Name)
+		c.assign(id.Name, types.TypeAndValue{Type: untyped(v), Value: v})
+		converted[id.Name] = true
+		return true
+	}
+	ast.Inspect(x, convert)
+	if err != nil {
+		return 0, err
+	}
+	if len(converted) == 0 {
+		return 0, fmt.Errorf("no quantity to convert to %s: %q", targetUnit, expr)
+	}
+	val, err := c.eval(expr)
+	if err != nil {
+		return 0, err
+	}
+	if constant.ToFloat(val).Kind() == constant.Unknown {
+		return 0, fmt.Errorf("not representable as a float (%v): %q", val.Kind(), expr)
+	}
+	f, _ := constant.Float64Val(constant.ToFloat(constant.BinaryOp(val, token.QUO, target.factor)))
+	return f, nil
+}
+
+// InUnitsOf evaluates 'expr', and returns it as a multiple of the variable
+// 'varName', used as a unit: with s = 1, h = 3600*s and d = 86400*s,
+// InUnitsOf("2*d", "h") is 48.
+//
+// Unlike [Scope.InUnit], there is no conversion table nor dimension:
+// variables are their own units. An error is returned if 'varName' is not
+// a variable, or if it is zero. [Scope.AssignIn] remembers the unit of a
+// variable.
+func (s Scope) InUnitsOf(expr, varName string) (float64, error) {
+	u, ok := s.Get(varName)
+	if !ok {
+		return 0, fmt.Errorf("undefined: %s", varName)
+	}
+	if u = constant.ToFloat(u); u.Kind() != constant.Float {
+		return 0, fmt.Errorf("not representable as a float (%v): %s", u.Kind(), varName)
+	}
+	if constant.Sign(u) == 0 {
+		return 0, fmt.Errorf("cannot express in units of %s, it is zero: %q", varName, expr)
+	}
+	val, err := s.eval(expr)
+	if err != nil {
+		return 0, err
+	}
+	fval := constant.ToFloat(val)
+	if fval.Kind() != constant.Float {
+		return 0, fmt.Errorf("not representable as a float (%v): %q", val.Kind(), expr)
+	}
+	f, _ := constant.Float64Val(constant.BinaryOp(fval, token.QUO, u))
+	return f, nil
+}
+
+// AssignIn is like [Scope.Assign], and tags the variable 'name' with the
+// display unit 'unitVar', another variable used as a unit, see
+// [Scope.InUnitsOf]. [Scope.Display] then formats 'name' in this unit.
+//
+// An error is returned if 'unitVar' is not a variable.
+func (s *Scope) AssignIn(name, expr, unitVar string) error {
+	if _, ok := s.Get(unitVar); !ok {
+		return fmt.Errorf("undefined: %s", unitVar)
+	}
+	if s.p != nil && s.p.Scope().Lookup(name) != nil {
+		return nil // like Assign, existing variables are not changed.
+	}
+	if err := s.Assign(name, expr); err != nil {
+		return err
+	}
+	s.display = maps.Clone(s.display) // copies of s are not changed.
+	if s.display == nil {
+		s.display = make(map[string]string)
+	}
+	s.display[name] = unitVar
+	return nil
+}
+
+// Display formats the variable 'name' in its display unit, set by
+// [Scope.AssignIn]: with d assigned "2*86400*s" in "h", Display("d") is
+// "48 h". Variables without a display unit are formatted alone.
+func (s Scope) Display(name string) (string, error) {
+	unitVar, ok := s.display[name]
+	if !ok {
+		f, err := s.Float64(name)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	}
+	f, err := s.InUnitsOf(name, unitVar)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64) + " " + unitVar, nil
+}
@@ -0,0 +1,122 @@
+package calc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInUnit(t *testing.T) {
+	var s Scope
+	s.Assign("n", "3")
+	for _, def := range [][3]string{{"x", "1", "ft"}, {"y", "2", "m"}, {"w", "1.5", "lb"}, {"d", "90", "min"}} {
+		if err := s.AssignUnitExpr(def[0], def[1], def[2]); err != nil {
+			t.Fatalf("AssignUnitExpr(%q, %q, %q) unexpected error: %v", def[0], def[1], def[2], err)
+		}
+	}
+
+	tests := []struct {
+		expr, unit string
+		want       float64
+	}{
+		{"2*x", "m", 0.6096},
+		{"x", "in", 12},
+		{"x + y", "cm", 230.48},
+		{"n*y", "km", 0.006},
+		{"w", "oz", 24},
+		{"d", "h", 1.5},
+	}
+	for _, test := range tests {
+		got, err := s.InUnit(test.expr, test.unit)
+		if err != nil {
+			t.Errorf("InUnit(%q, %q) unexpected error: %v", test.expr, test.unit, err)
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("InUnit(%q, %q) = %v, want %v", test.expr, test.unit, got, test.want)
+		}
+	}
+
+	for _, test := range [][2]string{{"x", "s"}, {"x + d", "m"}, {"n", "m"}, {"x", "furlong"}, {"x +", "m"}} {
+		if _, err := s.InUnit(test[0], test[1]); err == nil {
+			t.Errorf("InUnit(%q, %q) expected an error", test[0], test[1])
+		}
+	}
+	if err := s.AssignUnitExpr("z", "1", "furlong"); err == nil {
+		t.Errorf("AssignUnitExpr with an unknown unit expected an error")
+	}
+	if v, _ := s.Get("x"); v.String() != "1" {
+		t.Errorf("InUnit changed x to %v", v)
+	}
+
+	c := s
+	c.AssignUnitExpr("q", "1", "ft")
+	s.Assign("q", "1")
+	if got, err := s.InUnit("q", "m"); err == nil {
+		t.Errorf("InUnit(\"q\", \"m\") = %v, want an error: q is a quantity in a copy only", got)
+	}
+
+	s.Delete("x")
+	s.Assign("x", "1")
+	if got, err := s.InUnit("x", "m"); err == nil {
+		t.Errorf("InUnit(\"x\", \"m\") after Delete(\"x\") = %v, want an error: x is not a quantity", got)
+	}
+}
+
+func TestInUnitsOf(t *testing.T) {
+	var c Scope
+	c.Assign("s", "1")
+	c.Assign("h", "3600*s")
+	c.Assign("d", "24*h")
+	c.Assign("zero", "0")
+	c.Assign("name", `"h"`)
+	tests := []struct {
+		expr, unit string
+		want       float64
+	}{
+		{"2*d", "h", 48},
+		{"90*60*s", "h", 1.5},
+		{"h", "d", 1 / 24.},
+		{"1", "s", 1},
+	}
+	for _, test := range tests {
+		got, err := c.InUnitsOf(test.expr, test.unit)
+		if err != nil {
+			t.Errorf("InUnitsOf(%q, %q) unexpected error: %v", test.expr, test.unit, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("InUnitsOf(%q, %q) = %v, want %v", test.expr, test.unit, got, test.want)
+		}
+	}
+	for _, test := range [][2]string{{"d", "week"}, {"d", "zero"}, {"d", "name"}, {`"d"`, "h"}, {"d +", "h"}} {
+		if _, err := c.InUnitsOf(test[0], test[1]); err == nil {
+			t.Errorf("InUnitsOf(%q, %q) expected an error", test[0], test[1])
+		}
+	}
+}
+
+func TestAssignIn(t *testing.T) {
+	var c Scope
+	c.Assign("s", "1")
+	c.Assign("h", "3600*s")
+	if err := c.AssignIn("d", "2*86400*s", "h"); err != nil {
+		t.Fatalf("AssignIn() unexpected error: %v", err)
+	}
+	if got, err := c.Display("d"); err != nil || got != "48 h" {
+		t.Errorf("Display(\"d\") = %q, %v, want \"48 h\"", got, err)
+	}
+	if got, err := c.Display("h"); err != nil || got != "3600" {
+		t.Errorf("Display(\"h\") = %q, %v, want \"3600\"", got, err)
+	}
+	if err := c.AssignIn("w", "7*d", "week"); err == nil {
+		t.Errorf("AssignIn() with an undefined unit expected an error")
+	}
+	if _, err := c.Display("w"); err == nil {
+		t.Errorf("Display(\"w\") expected an error")
+	}
+	c.Delete("d")
+	c.Assign("d", "86400*s")
+	if got, err := c.Display("d"); err != nil || got != "86400" {
+		t.Errorf("Display(\"d\") after Delete = %q, %v, want \"86400\"", got, err)
+	}
+}